@@ -0,0 +1,94 @@
+package chttp
+
+import (
+	"io"
+)
+
+// Body is a streamed request body with the optional known length and the factory to rewind it.
+// Body could be used in any method, which accepts an io.Reader as the request body.
+type Body struct {
+	// Reader is the body content.
+	Reader io.Reader
+	// Length is the size of the body in bytes, zero or negative value means that the length is unknown.
+	Length int64
+	// GetBody returns a new copy of the body, it's used to resend the body on redirects and retries.
+	GetBody func() (io.ReadCloser, error)
+}
+
+// NewBody creates a Body with the given reader, length, and optional rewinding factory.
+func NewBody(reader io.Reader, length int64, getBody func() (io.ReadCloser, error)) *Body {
+	return &Body{
+		Reader:  reader,
+		Length:  length,
+		GetBody: getBody,
+	}
+}
+
+// Read reads the body content.
+func (b *Body) Read(p []byte) (int, error) {
+	return b.Reader.Read(p)
+}
+
+// Close closes the body content, if it implements io.Closer.
+func (b *Body) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// limitBody returns the reader, which fails with the ErrResponseTooLarge error after reading more than limit bytes.
+// Zero or negative limit means no limit.
+func limitBody(reader io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return reader
+	}
+	return &limitedReader{reader: reader, left: limit}
+}
+
+type limitedReader struct {
+	reader io.Reader
+	left   int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.left < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > r.left+1 {
+		p = p[:r.left+1]
+	}
+	n, err := r.reader.Read(p)
+	if int64(n) > r.left {
+		n, r.left = int(r.left), -1
+		return n, ErrResponseTooLarge
+	}
+	r.left -= int64(n)
+	return n, err
+}
+
+// errorBody returns the prefix of the data kept in the Error.
+func errorBody(data []byte) []byte {
+	if len(data) > maxErrorBodySize {
+		return data[:maxErrorBodySize:maxErrorBodySize]
+	}
+	return data
+}
+
+// prefixReader keeps the first limit bytes read from the reader.
+type prefixReader struct {
+	reader io.Reader
+	limit  int
+	data   []byte
+}
+
+func (r *prefixReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if left := r.limit - len(r.data); left > 0 {
+		if n < left {
+			left = n
+		}
+		r.data = append(r.data, p[:left]...)
+	}
+	return n, err
+}
@@ -0,0 +1,154 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testStreamServer redirects the first request with the 307 status code, and returns the body length
+// and the `Content-Length` header of the second one.
+func testStreamServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/" {
+			_, _ = io.Copy(io.Discard, request.Body)
+			http.Redirect(writer, request, "/redirected", http.StatusTemporaryRedirect)
+			return
+		}
+		data, err := io.ReadAll(request.Body)
+		if err != nil {
+			t.Errorf("ReadAll() error = %v", err)
+		}
+		writer.Header().Set("X-Content-Length", strconv.FormatInt(request.ContentLength, 10))
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(data)
+	}))
+}
+
+func TestClient_StreamRequest(t *testing.T) {
+	server := testStreamServer(t)
+	defer server.Close()
+
+	const size = 1 << 20
+	content := bytes.Repeat([]byte("0123456789abcdef"), size/16)
+	getBody := func() (io.ReadCloser, error) {
+		return io.NopCloser(io.MultiReader(bytes.NewReader(content))), nil
+	}
+	reader, _ := getBody()
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantLength string
+	}{
+		{name: "known length", body: NewBody(reader, size, getBody), wantLength: strconv.Itoa(size)},
+		{name: "reader", body: strings.NewReader("data"), wantLength: "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewClient(nil).StreamRequest(context.Background(), http.MethodPost, server.URL, tt.body)
+			if err != nil {
+				t.Errorf("StreamRequest() error = %v", err)
+				return
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/redirected" {
+				t.Errorf("StreamRequest() wrong response: %d %s", resp.StatusCode, resp.Request.URL)
+			}
+			if got := resp.Header.Get("X-Content-Length"); got != tt.wantLength {
+				t.Errorf("StreamRequest() wrong content length: %s, want %s", got, tt.wantLength)
+			}
+		})
+	}
+}
+
+func TestJSONClient_StreamRequest(t *testing.T) {
+	server := testStreamServer(t)
+	defer server.Close()
+
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	body := make([]item, 10000)
+	for i := range body {
+		body[i] = item{ID: i, Name: strconv.Itoa(i)}
+	}
+
+	result, err := NewGenericJSONClient[[]item](nil).StreamRequest(context.Background(), http.MethodPost, server.URL, body)
+	if err != nil {
+		t.Errorf("StreamRequest() error = %v", err)
+		return
+	}
+	if len(result) != len(body) || result[len(body)-1] != body[len(body)-1] {
+		t.Errorf("StreamRequest() wrong result: %d", len(result))
+	}
+
+	var empty json.RawMessage
+	if err = NewJSON(nil).StreamRequest(context.Background(), http.MethodPost, server.URL, nil, &empty); err != nil {
+		t.Errorf("StreamRequest() error = %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("StreamRequest() wrong result: %s", empty)
+	}
+}
+
+func TestJSONClient_StreamRequest_error(t *testing.T) {
+	err := NewJSON(nil).StreamRequest(context.Background(), http.MethodPost, "invalid url", 123, nil)
+	if err == nil {
+		t.Errorf("StreamRequest() error wanted")
+	}
+}
+
+func TestClient_StreamVerbs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, _ := io.ReadAll(request.Body)
+		_ = json.NewEncoder(writer).Encode(request.Method + " " + strings.TrimSpace(string(data)))
+	}))
+	defer server.Close()
+
+	client := NewGenericJSONClient[string](nil)
+	tests := []struct {
+		method  string
+		raw     func(ctx context.Context, url string, body io.Reader) (*http.Response, error)
+		json    func(ctx context.Context, url string, body interface{}, result interface{}) error
+		generic func(ctx context.Context, url string, body interface{}) (string, error)
+	}{
+		{method: http.MethodPost, raw: client.Client.StreamPOST, json: client.JSONClient.StreamPOST, generic: client.StreamPOST},
+		{method: http.MethodPut, raw: client.Client.StreamPUT, json: client.JSONClient.StreamPUT, generic: client.StreamPUT},
+		{method: http.MethodPatch, raw: client.Client.StreamPATCH, json: client.JSONClient.StreamPATCH, generic: client.StreamPATCH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			want := tt.method + ` "data"`
+			resp, err := tt.raw(context.Background(), server.URL, strings.NewReader(`"data"`))
+			if err != nil {
+				t.Fatalf("Client.Stream%s() error = %v", tt.method, err)
+			}
+			var got string
+			_ = json.NewDecoder(resp.Body).Decode(&got)
+			_ = resp.Body.Close()
+			if got != want {
+				t.Errorf("Client.Stream%s() got = %q, want %q", tt.method, got, want)
+			}
+
+			got = ""
+			if err = tt.json(context.Background(), server.URL, "data", &got); err != nil || got != want {
+				t.Errorf("JSONClient.Stream%s() got = %q, want %q, error = %v", tt.method, got, want, err)
+			}
+
+			got, err = tt.generic(context.Background(), server.URL, "data")
+			if err != nil || got != want {
+				t.Errorf("GenericJSONClient.Stream%s() got = %q, want %q, error = %v", tt.method, got, want, err)
+			}
+		})
+	}
+}
@@ -3,17 +3,25 @@ package chttp
 import (
 	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"net/url"
 	"sync"
 )
 
 // Client is an HTTP client wrapper that injects middleware transport in the http.Client
 // and provides a list of useful methods.
 type Client struct {
-	HTTP        *http.Client
-	middlewares []Middleware
-	base        http.RoundTripper
-	mu          sync.RWMutex
+	HTTP            *http.Client
+	middlewares     []namedMiddleware
+	base            http.RoundTripper
+	baseURL         *url.URL
+	baseURLErr      error
+	maxResponseSize int64
+	jsonConfig      *JSONConfig
+	policy          *StatusPolicy
+	problems        map[string]func(problem *Problem) error
+	mu              sync.RWMutex
 }
 
 // NewClient is a constructor for the Client. If no http.Client provided as an argument, a new client will be created.
@@ -25,7 +33,7 @@ func NewClient(client *http.Client, options ...Option) *Client {
 
 	result := &Client{
 		HTTP:        &clone,
-		middlewares: []Middleware{},
+		middlewares: []namedMiddleware{},
 		base:        clone.Transport,
 	}
 	clone.Transport = result.transport()
@@ -41,15 +49,19 @@ func NewClient(client *http.Client, options ...Option) *Client {
 func (c *Client) With(middleware ...Middleware) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.middlewares = append(c.middlewares, middleware...)
+	for _, m := range middleware {
+		c.middlewares = append(c.middlewares, namedMiddleware{middleware: m})
+	}
 }
 
 func (c *Client) getMiddlewares() []Middleware {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	clone := make([]Middleware, len(c.middlewares))
-	copy(clone, c.middlewares)
-	return clone
+	middlewares := make([]Middleware, len(c.middlewares))
+	for i, entry := range c.middlewares {
+		middlewares[i] = entry.middleware
+	}
+	return middlewares
 }
 
 // JSON creates a JSONClient wrapper with the given Client as a basic one.
@@ -58,16 +70,52 @@ func (c *Client) JSON() *JSONClient {
 }
 
 // Do is the proxy to the same method in the http.Client.
+// Relative request URL will be resolved against the base URL, if it was set with the WithBaseURL option.
 func (c *Client) Do(request *http.Request) (*http.Response, error) {
+	if c.baseURLErr != nil {
+		return nil, c.baseURLErr
+	}
+	if c.baseURL != nil && request.URL != nil && !request.URL.IsAbs() && request.URL.Host == "" {
+		request = request.WithContext(request.Context())
+		request.URL = resolveURL(c.baseURL, request.URL)
+		request.Host = request.URL.Host
+	}
 	return c.HTTP.Do(request)
 }
 
 // Request creates a http.Request with the given arguments and calls the Client.Do method.
 func (c *Client) Request(ctx context.Context, method string, url string, body []byte) (*http.Response, error) {
-	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
+	return c.request(ctx, method, url, bytes.NewBuffer(body), nil)
+}
+
+// StreamRequest creates a http.Request with the given body stream and calls the Client.Do method.
+// Use the *Body as the body argument to set the known length and the factory to rewind the body.
+func (c *Client) StreamRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Response, error) {
+	return c.request(ctx, method, url, body, nil)
+}
+
+func (c *Client) request(
+	ctx context.Context,
+	method string,
+	url string,
+	body io.Reader,
+	params *requestParams,
+) (*http.Response, error) {
+	url, err := params.buildURL(url)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
+	if stream, ok := body.(*Body); ok {
+		if stream.Length > 0 {
+			request.ContentLength = stream.Length
+		}
+		request.GetBody = stream.GetBody
+	}
+	params.apply(request)
 	return c.Do(request)
 }
 
@@ -123,6 +171,21 @@ func (c *Client) PATCH(ctx context.Context, url string, body ...[]byte) (*http.R
 	return c.Request(ctx, http.MethodPatch, url, getBody(body))
 }
 
+// StreamPOST is an alias to do the StreamRequest with the http.MethodPost method.
+func (c *Client) StreamPOST(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	return c.StreamRequest(ctx, http.MethodPost, url, body)
+}
+
+// StreamPUT is an alias to do the StreamRequest with the http.MethodPut method.
+func (c *Client) StreamPUT(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	return c.StreamRequest(ctx, http.MethodPut, url, body)
+}
+
+// StreamPATCH is an alias to do the StreamRequest with the http.MethodPatch method.
+func (c *Client) StreamPATCH(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	return c.StreamRequest(ctx, http.MethodPatch, url, body)
+}
+
 // DELETE is an alias to do the Request with the http.MethodDelete method.
 func (c *Client) DELETE(ctx context.Context, url string, body ...[]byte) (*http.Response, error) {
 	return c.Request(ctx, http.MethodDelete, url, getBody(body))
@@ -151,11 +214,22 @@ func (c *Client) Clone() *Client {
 	httpClient := *c.HTTP
 
 	clone := &Client{
-		HTTP:        &httpClient,
-		middlewares: make([]Middleware, len(c.middlewares)),
-		base:        c.base,
+		HTTP:            &httpClient,
+		middlewares:     make([]namedMiddleware, len(c.middlewares)),
+		base:            c.base,
+		baseURL:         c.baseURL,
+		baseURLErr:      c.baseURLErr,
+		maxResponseSize: c.maxResponseSize,
+		jsonConfig:      c.jsonConfig,
+		policy:          c.policy,
 	}
 	copy(clone.middlewares, c.middlewares)
+	if c.problems != nil {
+		clone.problems = make(map[string]func(problem *Problem) error, len(c.problems))
+		for typeURI, constructor := range c.problems {
+			clone.problems[typeURI] = constructor
+		}
+	}
 	httpClient.Transport = clone.transport()
 
 	return clone
@@ -162,6 +162,43 @@ func TestClient_With(t *testing.T) {
 	_ = req.Body.Close()
 }
 
+func TestClient_With_retry(t *testing.T) {
+	var calls, inner int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls++
+		if calls == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(nil)
+	c.With(func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		response, err := next(request)
+		if err == nil && response.StatusCode == http.StatusServiceUnavailable {
+			_ = response.Body.Close()
+			return next(request)
+		}
+		return response, err
+	})
+	c.With(func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		inner++
+		return next(request)
+	})
+
+	resp, err := c.GET(context.TODO(), server.URL)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 || inner != 2 {
+		t.Errorf("wrong retry: status=%d calls=%d inner=%d", resp.StatusCode, calls, inner)
+	}
+}
+
 func TestClient_JSON(t *testing.T) {
 	body, _ := json.Marshal(123)
 	result, _ := json.Marshal(456)
@@ -0,0 +1,440 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Codec encodes the request bodies and decodes the response bodies of the CodecClient.
+type Codec interface {
+	// ContentType returns the media type of the encoded data, e.g. `application/json`.
+	ContentType() string
+	// Encode writes the encoded value into the writer.
+	Encode(writer io.Writer, value interface{}) error
+	// Decode reads the value from the reader. Returns io.EOF if the reader is empty.
+	Decode(reader io.Reader, value interface{}) error
+}
+
+// JSONCodec is the Codec of the `application/json` data based on the encoding/json package.
+type JSONCodec struct {
+	// Config is the encoding and decoding configuration, default settings are used if it's nil.
+	Config *JSONConfig
+}
+
+// ContentType returns `application/json`.
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+// Encode marshals the value with the json.Encoder.
+func (c JSONCodec) Encode(writer io.Writer, value interface{}) error {
+	return c.Config.encode(writer, value)
+}
+
+// Decode unmarshals the single top-level value with the json.Decoder without buffering it in memory.
+// The data is read into the memory, if the custom JSONConfig.Unmarshal function is set.
+func (c JSONCodec) Decode(reader io.Reader, value interface{}) error {
+	if c.Config != nil && c.Config.Unmarshal != nil {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return io.EOF
+		}
+		return c.Config.Unmarshal(data, value)
+	}
+	decoder := c.Config.newDecoder(reader)
+	if err := decoder.Decode(value); err != nil {
+		return err
+	}
+	err := decoder.Decode(new(json.RawMessage))
+	if err == nil {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// XMLCodec is the Codec of the `application/xml` data based on the encoding/xml package.
+type XMLCodec struct{}
+
+// ContentType returns `application/xml`.
+func (XMLCodec) ContentType() string {
+	return "application/xml"
+}
+
+// Encode marshals the value with the xml.Encoder.
+func (XMLCodec) Encode(writer io.Writer, value interface{}) error {
+	return xml.NewEncoder(writer).Encode(value)
+}
+
+// Decode unmarshals the value with the xml.Decoder.
+func (XMLCodec) Decode(reader io.Reader, value interface{}) error {
+	return xml.NewDecoder(reader).Decode(value)
+}
+
+// GobCodec is the Codec of the `application/x-gob` data based on the encoding/gob package.
+type GobCodec struct{}
+
+// ContentType returns `application/x-gob`.
+func (GobCodec) ContentType() string {
+	return "application/x-gob"
+}
+
+// Encode marshals the value with the gob.Encoder.
+func (GobCodec) Encode(writer io.Writer, value interface{}) error {
+	return gob.NewEncoder(writer).Encode(value)
+}
+
+// Decode unmarshals the value with the gob.Decoder.
+func (GobCodec) Decode(reader io.Reader, value interface{}) error {
+	return gob.NewDecoder(reader).Decode(value)
+}
+
+// TextCodec is the Codec of the `text/plain` data. It encodes string, []byte, encoding.TextMarshaler,
+// fmt.Stringer, and basic types, and decodes into *string, *[]byte, and encoding.TextUnmarshaler.
+type TextCodec struct{}
+
+// ContentType returns `text/plain; charset=utf-8`.
+func (TextCodec) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+// Encode writes the text representation of the value.
+func (TextCodec) Encode(writer io.Writer, value interface{}) error {
+	var data []byte
+	switch value := value.(type) {
+	case string:
+		data = []byte(value)
+	case []byte:
+		data = value
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return err
+		}
+		data = text
+	case fmt.Stringer:
+		data = []byte(value.String())
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		data = []byte(fmt.Sprint(value))
+	default:
+		return fmt.Errorf("unsupported text type: %T", value)
+	}
+	_, err := writer.Write(data)
+	return err
+}
+
+// Decode reads the whole text into the value.
+func (TextCodec) Decode(reader io.Reader, value interface{}) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case *string:
+		*value = string(data)
+	case *[]byte:
+		*value = data
+	case encoding.TextUnmarshaler:
+		return value.UnmarshalText(data)
+	case *interface{}:
+		*value = string(data)
+	default:
+		return fmt.Errorf("unsupported text type: %T", value)
+	}
+	return nil
+}
+
+// FormCodec is the Codec of the `application/x-www-form-urlencoded` data. It encodes the same bodies as
+// the FormClient, except the *Multipart, and decodes into *url.Values, *map[string][]string,
+// and *map[string]string.
+type FormCodec struct{}
+
+// ContentType returns `application/x-www-form-urlencoded`.
+func (FormCodec) ContentType() string {
+	return "application/x-www-form-urlencoded"
+}
+
+// Encode writes the url-encoded form.
+func (FormCodec) Encode(writer io.Writer, value interface{}) error {
+	values, err := formValues(value, newRequestParams())
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, values.Encode())
+	return err
+}
+
+// Decode parses the url-encoded form into the value.
+func (FormCodec) Decode(reader io.Reader, value interface{}) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	values, err := neturl.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case *neturl.Values:
+		*value = values
+	case *map[string][]string:
+		*value = values
+	case *map[string]string:
+		*value = make(map[string]string, len(values))
+		for name := range values {
+			(*value)[name] = values.Get(name)
+		}
+	case *interface{}:
+		*value = values
+	default:
+		return fmt.Errorf("unsupported form type: %T", value)
+	}
+	return nil
+}
+
+// CodecClient is an HTTP client wrapper around the Client with automated encoding of the request body
+// and decoding of the response body with the list of the codecs.
+//
+// The request body is encoded with the first codec, the response body is decoded with the codec matching
+// the response Content-Type (`application/problem+json` matches the `application/json` codec by the suffix),
+// or with the first codec if there is no match. The Accept header is built from all codecs in the order
+// of preference, unless it's set explicitly.
+type CodecClient struct {
+	*Client
+	codecs []Codec
+}
+
+// Codecs wraps Client with the CodecClient. JSONCodec with the WithJSONConfig configuration is used
+// if no codecs are given.
+func Codecs(client *Client, codecs ...Codec) *CodecClient {
+	if len(codecs) == 0 {
+		codecs = client.jsonCodecs()
+	}
+	return &CodecClient{
+		Client: client,
+		codecs: codecs,
+	}
+}
+
+// NewCodecClient creates a CodecClient with new Client based on given http.Client.
+func NewCodecClient(client *http.Client, codecs []Codec, options ...Option) *CodecClient {
+	return Codecs(NewClient(client, options...), codecs...)
+}
+
+// Codecs creates a CodecClient wrapper with the given Client as a basic one.
+func (c *Client) Codecs(codecs ...Codec) *CodecClient {
+	return Codecs(c, codecs...)
+}
+
+// Accept returns the value of the Accept header built from the codecs.
+func (c *CodecClient) Accept() string {
+	types := make([]string, 0, len(c.codecs))
+	seen := make(map[string]bool, len(c.codecs))
+	for _, codec := range c.codecs {
+		mediaType := codecMediaType(codec)
+		if seen[mediaType] {
+			continue
+		}
+		seen[mediaType] = true
+		if quality := 10 - len(types); len(types) > 0 {
+			if quality < 1 {
+				quality = 1
+			}
+			mediaType += ";q=0." + strconv.Itoa(quality)
+		}
+		types = append(types, mediaType)
+	}
+	return strings.Join(types, ", ")
+}
+
+// Request prepares the request by encoding request body with the first codec and tries to decode response
+// with the CodecClient.UnmarshalHTTPResponse function. Fields tagged with `path`, `query`, and `header` tags
+// are lifted as in the JSONClient.
+func (c *CodecClient) Request(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+	result interface{},
+) (err error) {
+	body, params, err := splitParams(body)
+	if err != nil {
+		return err
+	}
+	if params == nil {
+		params = newRequestParams()
+	}
+	var reader io.Reader
+	if body != nil {
+		buffer := new(bytes.Buffer)
+		if err = c.codecs[0].Encode(buffer, body); err != nil {
+			return NewError(nil, nil, fmt.Errorf("marshaling request error: %w", err))
+		}
+		params.header.Set("Content-Type", c.codecs[0].ContentType())
+		reader = buffer
+	}
+	if params.header.Get("Accept") == "" {
+		params.header.Set("Accept", c.Accept())
+	}
+	res, err := c.Client.request(ctx, method, url, reader, params)
+	return c.UnmarshalHTTPResponse(res, err, result)
+}
+
+// UnmarshalHTTPResponse tries to decode the response body into the given result interface with the codec
+// matching the response Content-Type. Result should be reference type and not nil.
+func (c *CodecClient) UnmarshalHTTPResponse(response *http.Response, httpErr error, result interface{}) (err error) {
+	return c.Client.decodeHTTPResponse(response, httpErr, result, nil, c.codecs)
+}
+
+// Method returns a function implementation of the HTTP Method from the Client by its name.
+// Returns Client.GET as the default method.
+func (c *CodecClient) Method(
+	method string,
+) func(ctx context.Context, url string, body interface{}, result interface{}) error {
+	switch method {
+	case http.MethodHead:
+		return c.HEAD
+	case http.MethodPost:
+		return c.POST
+	case http.MethodPut:
+		return c.PUT
+	case http.MethodPatch:
+		return c.PATCH
+	case http.MethodDelete:
+		return c.DELETE
+	case http.MethodConnect:
+		return c.CONNECT
+	case http.MethodOptions:
+		return c.OPTIONS
+	case http.MethodTrace:
+		return c.TRACE
+	case http.MethodGet:
+		fallthrough
+	default:
+		return c.GET
+	}
+}
+
+// GET is an alias to do the Request with the http.MethodGet method.
+func (c *CodecClient) GET(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodGet, url, body, result)
+}
+
+// HEAD is an alias to do the Request with the http.MethodHead method.
+func (c *CodecClient) HEAD(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodHead, url, body, result)
+}
+
+// POST is an alias to do the Request with the http.MethodPost method.
+func (c *CodecClient) POST(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPost, url, body, result)
+}
+
+// PUT is an alias to do the Request with the http.MethodPut method.
+func (c *CodecClient) PUT(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPut, url, body, result)
+}
+
+// PATCH is an alias to do the Request with the http.MethodPatch method.
+func (c *CodecClient) PATCH(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPatch, url, body, result)
+}
+
+// DELETE is an alias to do the Request with the http.MethodDelete method.
+func (c *CodecClient) DELETE(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodDelete, url, body, result)
+}
+
+// CONNECT is an alias to do the Request with the http.MethodConnect method.
+func (c *CodecClient) CONNECT(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodConnect, url, body, result)
+}
+
+// OPTIONS is an alias to do the Request with the http.MethodOptions method.
+func (c *CodecClient) OPTIONS(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodOptions, url, body, result)
+}
+
+// TRACE is an alias to do the Request with the http.MethodTrace method.
+func (c *CodecClient) TRACE(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodTrace, url, body, result)
+}
+
+// Clone will clone an instance of the CodecClient without references to the old one.
+func (c *CodecClient) Clone() *CodecClient {
+	return Codecs(c.Client.Clone(), append([]Codec(nil), c.codecs...)...)
+}
+
+// selectCodec returns the codec matching the response Content-Type exactly, or by the structured syntax suffix
+// (RFC 6839), or the first codec.
+func selectCodec(codecs []Codec, response *http.Response) Codec {
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if err != nil || len(codecs) == 1 {
+		return codecs[0]
+	}
+	for _, codec := range codecs {
+		if codecMediaType(codec) == mediaType {
+			return codec
+		}
+	}
+	for _, codec := range codecs {
+		_, subtype, _ := strings.Cut(codecMediaType(codec), "/")
+		if strings.HasSuffix(mediaType, "+"+subtype) {
+			return codec
+		}
+	}
+	return codecs[0]
+}
+
+// unmarshal decodes the whole data with the codec, the empty data is reported as the io.ErrUnexpectedEOF.
+func unmarshal(codec Codec, data []byte, value interface{}) error {
+	err := codec.Decode(bytes.NewReader(data), value)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (c *Client) jsonCodecs() []Codec {
+	return []Codec{JSONCodec{Config: c.jsonConfig}}
+}
+
+func codecMediaType(codec Codec) string {
+	mediaType, _, err := mime.ParseMediaType(codec.ContentType())
+	if err != nil {
+		return codec.ContentType()
+	}
+	return mediaType
+}
+
+// unwrapResult removes the extra *interface{} references around the result pointer, which are added
+// by the client methods, so the decoders without the interface indirection support could fill the result.
+func unwrapResult(result interface{}) interface{} {
+	for {
+		reference, ok := result.(*interface{})
+		if !ok || reference == nil || *reference == nil {
+			return result
+		}
+		if kind := reflect.TypeOf(*reference).Kind(); kind != reflect.Ptr {
+			return result
+		}
+		result = *reference
+	}
+}
@@ -0,0 +1,224 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"reflect"
+	"testing"
+)
+
+type testCodecPet struct {
+	XMLName xml.Name `json:"-" xml:"pet"`
+	ID      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+// testServerEcho responds with the request body and the Content-Type given in the `type` query parameter,
+// or the request Content-Type.
+func testServerEcho(t *testing.T, accept *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if accept != nil {
+			*accept = request.Header.Get("Accept")
+		}
+		contentType := request.URL.Query().Get("type")
+		if contentType == "" {
+			contentType = request.Header.Get("Content-Type")
+		}
+		writer.Header().Set("Content-Type", contentType)
+		if _, err := io.Copy(writer, request.Body); err != nil {
+			t.Errorf("Copy() error = %v", err)
+		}
+	}))
+}
+
+func TestCodecClient_Request(t *testing.T) {
+	pet := testCodecPet{ID: 10, Name: "Rex"}
+	tests := []struct {
+		name   string
+		codecs []Codec
+		body   interface{}
+		result func() interface{}
+		want   interface{}
+	}{
+		{
+			name:   "json",
+			codecs: nil,
+			body:   pet,
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "xml",
+			codecs: []Codec{XMLCodec{}, JSONCodec{}},
+			body:   pet,
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{XMLName: xml.Name{Local: "pet"}, ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "gob",
+			codecs: []Codec{GobCodec{}},
+			body:   pet,
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "text",
+			codecs: []Codec{TextCodec{}},
+			body:   42,
+			result: func() interface{} { return new(string) },
+			want:   func() *string { value := "42"; return &value }(),
+		},
+		{
+			name:   "form",
+			codecs: []Codec{FormCodec{}},
+			body:   map[string]string{"name": "Rex"},
+			result: func() interface{} { return new(neturl.Values) },
+			want:   &neturl.Values{"name": {"Rex"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServerEcho(t, nil)
+			defer server.Close()
+
+			result := tt.result()
+			err := NewCodecClient(nil, tt.codecs).POST(context.Background(), server.URL, tt.body, result)
+			if err != nil {
+				t.Fatalf("POST() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("POST() got = %#v, want %#v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecClient_Request_params(t *testing.T) {
+	type request struct {
+		XMLName xml.Name `json:"-" xml:"pet" form:"-"`
+		Owner   string   `path:"owner" json:"-" xml:"-"`
+		Trace   string   `header:"X-Trace-Id"`
+		ID      int      `json:"id" xml:"id"`
+		Name    string   `json:"name" xml:"name" form:"name"`
+	}
+	body := request{Owner: "john", Trace: "trace", ID: 10, Name: "Rex"}
+	tests := []struct {
+		name   string
+		codecs []Codec
+		result func() interface{}
+		want   interface{}
+	}{
+		{
+			name:   "json",
+			codecs: []Codec{JSONCodec{}},
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "xml",
+			codecs: []Codec{XMLCodec{}},
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{XMLName: xml.Name{Local: "pet"}, ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "gob",
+			codecs: []Codec{GobCodec{}},
+			result: func() interface{} { return new(testCodecPet) },
+			want:   &testCodecPet{ID: 10, Name: "Rex"},
+		},
+		{
+			name:   "form",
+			codecs: []Codec{FormCodec{}},
+			result: func() interface{} { return new(neturl.Values) },
+			want:   &neturl.Values{"ID": {"10"}, "name": {"Rex"}},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/owners/john/pets" || request.Header.Get("X-Trace-Id") != "trace" {
+			t.Errorf("wrong request: %s %v", request.URL, request.Header)
+		}
+		writer.Header().Set("Content-Type", request.Header.Get("Content-Type"))
+		_, _ = io.Copy(writer, request.Body)
+	}))
+	defer server.Close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.result()
+			err := NewCodecClient(nil, tt.codecs).POST(context.Background(), server.URL+"/owners/{owner}/pets", body, result)
+			if err != nil {
+				t.Fatalf("POST() error = %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("POST() got = %#v, want %#v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecClient_Request_contentType(t *testing.T) {
+	var accept string
+	server := testServerEcho(t, &accept)
+	defer server.Close()
+	client := NewClient(nil).Codecs(JSONCodec{}, XMLCodec{}, TextCodec{})
+
+	var text string
+	if err := client.POST(context.Background(), server.URL+"?type=text/plain", "hello", &text); err != nil || text != `"hello"`+"\n" {
+		t.Errorf("POST() got = %q, error = %v", text, err)
+	}
+	if want := "application/json, application/xml;q=0.9, text/plain;q=0.8"; accept != want {
+		t.Errorf("POST() wrong Accept = %q, want %q", accept, want)
+	}
+
+	var pet testCodecPet
+	if err := client.POST(context.Background(), server.URL+"?type=application/vnd.pet%2Bjson", testCodecPet{ID: 1}, &pet); err != nil || pet.ID != 1 {
+		t.Errorf("POST() got = %v, error = %v", pet, err)
+	}
+
+	type request struct {
+		Accept string `header:"Accept"`
+	}
+	if err := client.GET(context.Background(), server.URL, request{Accept: "application/xml"}, nil); err != nil || accept != "application/xml" {
+		t.Errorf("GET() wrong Accept = %q, error = %v", accept, err)
+	}
+}
+
+func TestSelectCodec(t *testing.T) {
+	codecs := []Codec{JSONCodec{}, XMLCodec{}, TextCodec{}}
+	tests := []struct {
+		contentType string
+		want        Codec
+	}{
+		{contentType: "", want: JSONCodec{}},
+		{contentType: "application/xml; charset=utf-8", want: XMLCodec{}},
+		{contentType: "text/plain", want: TextCodec{}},
+		{contentType: "application/problem+json", want: JSONCodec{}},
+		{contentType: "application/atom+xml", want: XMLCodec{}},
+		{contentType: "image/png", want: JSONCodec{}},
+	}
+	for _, tt := range tests {
+		response := &http.Response{Header: http.Header{"Content-Type": {tt.contentType}}}
+		if got := selectCodec(codecs, response); got != tt.want {
+			t.Errorf("selectCodec(%q) = %T, want %T", tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestCodec_Decode_empty(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec{}, XMLCodec{}, GobCodec{}} {
+		if err := codec.Decode(bytes.NewReader(nil), new(testCodecPet)); err != io.EOF {
+			t.Errorf("%T.Decode() error = %v, want io.EOF", codec, err)
+		}
+	}
+	var pet testCodecPet
+	if err := (TextCodec{}).Decode(bytes.NewReader(nil), &pet); err == nil {
+		t.Errorf("TextCodec.Decode() error wanted")
+	}
+	if err := (JSONCodec{}).Decode(bytes.NewReader([]byte("1 2")), new(int)); err == nil {
+		t.Errorf("JSONCodec.Decode() error wanted for the data after top-level value")
+	}
+}
@@ -0,0 +1,551 @@
+package chttp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMinSegmentSize is the default minimal size of the parallel download segment.
+	DefaultMinSegmentSize = 1 << 20
+	// DefaultDownloadRetryDelay is the default delay before the first retry of the download segment.
+	DefaultDownloadRetryDelay = time.Second
+)
+
+const (
+	downloadBufferSize    = 32 << 10
+	downloadSaveInterval  = time.Second
+	downloadMaxRetryDelay = 30 * time.Second
+)
+
+var errResourceChanged = errors.New("download: resource changed")
+
+// DownloadConfig is the configuration of the Client.Download and Client.DownloadFile methods.
+type DownloadConfig struct {
+	// Segments is the number of the parallel ranged requests. Used only if the server supports the range requests,
+	// and the size of the resource is known. One by default.
+	Segments int
+	// MinSegmentSize is the minimal size of the segment, DefaultMinSegmentSize by default.
+	MinSegmentSize int64
+	// Retries is the number of the attempts to continue the segment after the failure within the single call.
+	Retries int
+	// RetryDelay is the delay before the first retry, DefaultDownloadRetryDelay by default. The delay is doubled
+	// after each failed attempt up to 30 seconds.
+	RetryDelay time.Duration
+	// Checksum is the expected hex-encoded checksum of the resource, it's verified after the download.
+	// The destination should implement the io.ReaderAt to verify the checksum.
+	Checksum string
+	// Hash creates the hash of the checksum, sha256.New by default.
+	Hash func() hash.Hash
+	// Progress is called after each written chunk with the number of the written bytes and the total size of
+	// the resource, -1 if the size is unknown. Calls are serialized.
+	Progress func(written int64, total int64)
+	// State is the state of the download, which allows to resume it. It's updated during the download and
+	// could be persisted between the calls. Not resumable state is reset.
+	State *DownloadState
+}
+
+// DownloadState is the persistent state of the download, which allows to resume it with the `Range` requests.
+type DownloadState struct {
+	// ETag is the strong entity tag of the resource, it's validated with the `If-Range` header on resume.
+	ETag string `json:"etag,omitempty"`
+	// LastModified is the `Last-Modified` header of the resource, it's used if the ETag is not available.
+	LastModified string `json:"last_modified,omitempty"`
+	// Size is the total size of the resource, -1 if unknown.
+	Size int64 `json:"size"`
+	// Segments is the list of the segments of the resource.
+	Segments []DownloadSegment `json:"segments,omitempty"`
+}
+
+// DownloadSegment is the continuous part of the resource downloaded with the single ranged request.
+type DownloadSegment struct {
+	// Start is the offset of the segment.
+	Start int64 `json:"start"`
+	// Length is the length of the segment, -1 if unknown.
+	Length int64 `json:"length"`
+	// Written is the number of the already written bytes.
+	Written int64 `json:"written"`
+}
+
+// Download downloads the resource into the destination. The download could be resumed with the same
+// DownloadConfig.State, if it's interrupted: only the missing ranges are requested with the `Range` header,
+// and the `If-Range` header makes the server send the whole resource, if it was changed in between.
+// Large resources are split into parallel ranged segments with the DownloadConfig.Segments.
+func (c *Client) Download(ctx context.Context, url string, dst io.WriterAt, config DownloadConfig) error {
+	return newDownloader(c, url, dst, config).run(ctx)
+}
+
+// DownloadFile downloads the resource into the file. The data is written into the `path.part` file, and
+// the state of the download is stored in the `path.part.json` file, so the next call resumes the interrupted
+// download. The part file is renamed to the path after the successful download.
+func (c *Client) DownloadFile(ctx context.Context, url string, path string, config DownloadConfig) (err error) {
+	part := path + ".part"
+	statePath := part + ".json"
+	if config.State == nil {
+		config.State = loadDownloadState(part, statePath)
+	}
+	file, err := os.OpenFile(part, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	d := newDownloader(c, url, file, config)
+	d.save = func(data []byte) error {
+		return os.WriteFile(statePath, data, 0o644)
+	}
+	err = d.run(ctx)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("download: %w", closeErr)
+	}
+	if err != nil {
+		_ = d.saveState(true)
+		return err
+	}
+	_ = os.Remove(statePath)
+	if err = os.Rename(part, path); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	return nil
+}
+
+func loadDownloadState(part string, statePath string) *DownloadState {
+	state := new(DownloadState)
+	if _, err := os.Stat(part); err != nil {
+		return state
+	}
+	data, err := os.ReadFile(statePath)
+	if err != nil || json.Unmarshal(data, state) != nil {
+		return new(DownloadState)
+	}
+	return state
+}
+
+// resumable reports whether the state has the validator and the download could be continued.
+func (s *DownloadState) resumable() bool {
+	return len(s.Segments) > 0 && s.validator() != ""
+}
+
+// validator returns the value of the `If-Range` header.
+func (s *DownloadState) validator() string {
+	if s.ETag != "" {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+func (s *DownloadState) written() (written int64) {
+	for _, segment := range s.Segments {
+		written += segment.Written
+	}
+	return written
+}
+
+type downloader struct {
+	client *Client
+	url    string
+	dst    io.WriterAt
+	config DownloadConfig
+	state  *DownloadState
+	save   func(data []byte) error
+	mu     sync.Mutex
+	saved  time.Time
+}
+
+func newDownloader(client *Client, url string, dst io.WriterAt, config DownloadConfig) *downloader {
+	if config.State == nil {
+		config.State = new(DownloadState)
+	}
+	if config.MinSegmentSize <= 0 {
+		config.MinSegmentSize = DefaultMinSegmentSize
+	}
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultDownloadRetryDelay
+	}
+	return &downloader{
+		client: client,
+		url:    url,
+		dst:    dst,
+		config: config,
+		state:  config.State,
+	}
+}
+
+func (d *downloader) run(ctx context.Context) error {
+	err := errResourceChanged
+	if d.state.resumable() {
+		err = d.download(ctx, nil)
+	}
+	if err == errResourceChanged {
+		if err = d.reset(); err != nil {
+			return err
+		}
+		err = d.start(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return d.verify()
+}
+
+// reset clears the state and truncates the destination, if it's possible.
+func (d *downloader) reset() error {
+	d.mu.Lock()
+	*d.state = DownloadState{}
+	d.mu.Unlock()
+	if truncater, ok := d.dst.(interface{ Truncate(size int64) error }); ok {
+		if err := truncater.Truncate(0); err != nil {
+			return fmt.Errorf("download: %w", err)
+		}
+	}
+	return nil
+}
+
+// start requests the whole resource with the `Range` header to find out the size and the range support,
+// and plans the segments. The response is used for the first segment.
+func (d *downloader) start(ctx context.Context) error {
+	response, err := d.get(ctx, "bytes=0-", "")
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	if etag := response.Header.Get("ETag"); !strings.HasPrefix(etag, "W/") {
+		d.state.ETag = etag
+	}
+	d.state.LastModified = response.Header.Get("Last-Modified")
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		start, _, total, ok := parseContentRange(response.Header.Get("Content-Range"))
+		if !ok || start != 0 {
+			d.mu.Unlock()
+			_ = response.Body.Close()
+			return fmt.Errorf("download: unexpected Content-Range: %q", response.Header.Get("Content-Range"))
+		}
+		if total < 0 {
+			d.state.Segments = []DownloadSegment{{Start: 0, Length: -1}}
+		} else {
+			d.state.Segments = d.plan(total)
+		}
+		d.state.Size = total
+	case http.StatusOK:
+		d.state.Size = response.ContentLength
+		d.state.Segments = []DownloadSegment{{Start: 0, Length: response.ContentLength}}
+	case http.StatusRequestedRangeNotSatisfiable:
+		d.state.Size = 0
+		d.mu.Unlock()
+		_ = response.Body.Close()
+		return nil
+	default:
+		d.mu.Unlock()
+		return d.statusError(response)
+	}
+	d.mu.Unlock()
+	return d.download(ctx, response)
+}
+
+// plan splits the resource into the segments.
+func (d *downloader) plan(total int64) []DownloadSegment {
+	count := int64(d.config.Segments)
+	if limit := total / d.config.MinSegmentSize; count > limit {
+		count = limit
+	}
+	if count < 1 {
+		count = 1
+	}
+	size := (total + count - 1) / count
+	segments := make([]DownloadSegment, 0, count)
+	for start := int64(0); start < total || len(segments) == 0; start += size {
+		length := size
+		if start+length > total {
+			length = total - start
+		}
+		segments = append(segments, DownloadSegment{Start: start, Length: length})
+	}
+	return segments
+}
+
+// download downloads all incomplete segments in parallel. The first response is used for the first segment.
+func (d *downloader) download(ctx context.Context, first *http.Response) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	d.mu.Lock()
+	segments := len(d.state.Segments)
+	d.mu.Unlock()
+
+	errs := make(chan error, segments)
+	var wg sync.WaitGroup
+	for i := 0; i < segments; i++ {
+		var response *http.Response
+		if i == 0 {
+			response = first
+		}
+		wg.Add(1)
+		go func(i int, response *http.Response) {
+			defer wg.Done()
+			if err := d.segment(ctx, i, response); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(i, response)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// segment downloads the segment with the retries, the delay between the retries grows exponentially.
+func (d *downloader) segment(ctx context.Context, index int, response *http.Response) error {
+	delay := d.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := d.fetch(ctx, index, response)
+		response = nil
+		if err == nil || err == errResourceChanged || ctx.Err() != nil || attempt >= d.config.Retries {
+			return err
+		}
+		if cErr := new(Error); errors.As(err, &cErr) && !cErr.Retryable() {
+			return err
+		}
+		if err = waitDelay(ctx, delay); err != nil {
+			return err
+		}
+		if delay *= 2; delay > downloadMaxRetryDelay {
+			delay = downloadMaxRetryDelay
+		}
+	}
+}
+
+// waitDelay blocks for the delay or until the context is done.
+func waitDelay(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// fetch requests the missing range of the segment and writes it into the destination.
+func (d *downloader) fetch(ctx context.Context, index int, response *http.Response) (err error) {
+	d.mu.Lock()
+	segment := d.state.Segments[index]
+	validator := d.state.validator()
+	single := len(d.state.Segments) == 1
+	d.mu.Unlock()
+	offset := segment.Start + segment.Written
+	if response == nil {
+		if segment.Length >= 0 && segment.Written >= segment.Length {
+			return nil
+		}
+		if response, err = d.get(ctx, rangeHeader(segment), validator); err != nil {
+			return err
+		}
+		if err = d.checkResponse(response, offset, single); err != nil {
+			_ = response.Body.Close()
+			return err
+		}
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	reader := io.Reader(response.Body)
+	if segment.Length >= 0 {
+		reader = io.LimitReader(reader, segment.Length-segment.Written)
+	}
+	buffer := make([]byte, downloadBufferSize)
+	for {
+		n, err := reader.Read(buffer)
+		if n > 0 {
+			if _, err := d.dst.WriteAt(buffer[:n], offset); err != nil {
+				return fmt.Errorf("download: %w", err)
+			}
+			offset += int64(n)
+			d.progress(index, int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return fmt.Errorf("download: %w", err)
+		}
+	}
+	return d.finish(index)
+}
+
+// checkResponse checks that the response contains the requested range.
+func (d *downloader) checkResponse(response *http.Response, offset int64, single bool) error {
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		start, _, _, ok := parseContentRange(response.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("download: unexpected Content-Range: %q", response.Header.Get("Content-Range"))
+		}
+		return nil
+	case http.StatusOK:
+		if offset != 0 || !single {
+			return errResourceChanged
+		}
+		return nil
+	default:
+		return d.statusError(response)
+	}
+}
+
+// finish checks the length of the segment, the length of the segment of the unknown size is set.
+func (d *downloader) finish(index int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	segment := &d.state.Segments[index]
+	if segment.Length < 0 {
+		segment.Length = segment.Written
+		d.state.Size = segment.Written
+		return nil
+	}
+	if segment.Written < segment.Length {
+		return fmt.Errorf("download: %w", io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+func (d *downloader) progress(index int, written int64) {
+	d.mu.Lock()
+	d.state.Segments[index].Written += written
+	if d.config.Progress != nil {
+		d.config.Progress(d.state.written(), d.state.Size)
+	}
+	d.mu.Unlock()
+	_ = d.saveState(false)
+}
+
+// saveState saves the state at most once per the downloadSaveInterval, unless it's forced.
+func (d *downloader) saveState(force bool) error {
+	if d.save == nil {
+		return nil
+	}
+	d.mu.Lock()
+	if !force && time.Since(d.saved) < downloadSaveInterval {
+		d.mu.Unlock()
+		return nil
+	}
+	d.saved = time.Now()
+	data, err := json.Marshal(d.state)
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return d.save(data)
+}
+
+// verify checks the size and the checksum of the downloaded resource.
+func (d *downloader) verify() error {
+	written := d.state.written()
+	if d.state.Size >= 0 && written != d.state.Size {
+		return fmt.Errorf("%w: got %d bytes, want %d", ErrSizeMismatch, written, d.state.Size)
+	}
+	if d.config.Checksum == "" {
+		return nil
+	}
+	reader, ok := d.dst.(io.ReaderAt)
+	if !ok {
+		return fmt.Errorf("download: checksum verification requires io.ReaderAt destination")
+	}
+	digest := d.config.Hash()
+	if _, err := io.Copy(digest, io.NewSectionReader(reader, 0, written)); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	if sum := hex.EncodeToString(digest.Sum(nil)); !strings.EqualFold(sum, d.config.Checksum) {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, sum, d.config.Checksum)
+	}
+	return nil
+}
+
+func (d *downloader) get(ctx context.Context, ranges string, validator string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if ranges != "" {
+		request.Header.Set("Range", ranges)
+	}
+	if validator != "" {
+		request.Header.Set("If-Range", validator)
+	}
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, NewError(response, nil, fmt.Errorf("requesting error: %w", err))
+	}
+	return response, nil
+}
+
+func (d *downloader) statusError(response *http.Response) error {
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	return d.client.statusError(response, d.client.jsonCodecs())
+}
+
+// rangeHeader returns the `Range` header of the missing part of the segment.
+func rangeHeader(segment DownloadSegment) string {
+	offset := segment.Start + segment.Written
+	if segment.Length < 0 {
+		if offset == 0 {
+			return ""
+		}
+		return "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	}
+	return "bytes=" + strconv.FormatInt(offset, 10) + "-" + strconv.FormatInt(segment.Start+segment.Length-1, 10)
+}
+
+// parseContentRange parses the `Content-Range: bytes start-end/total` header, total is -1 if it's unknown.
+func parseContentRange(value string) (start int64, end int64, total int64, ok bool) {
+	value, found := cutPrefix(strings.TrimSpace(value), "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	ranges, size, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	first, last, found := strings.Cut(ranges, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, false
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, 0, false
+		}
+	}
+	return start, end, total, true
+}
+
+func cutPrefix(value string, prefix string) (string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, false
+	}
+	return value[len(prefix):], true
+}
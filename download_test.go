@@ -0,0 +1,308 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testWriterAt struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (w *testWriterAt) WriteAt(data []byte, offset int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if end := int(offset) + len(data); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	return copy(w.data[offset:], data), nil
+}
+
+func (w *testWriterAt) ReadAt(data []byte, offset int64) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return bytes.NewReader(w.data).ReadAt(data, offset)
+}
+
+func (w *testWriterAt) Truncate(size int64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.data = w.data[:size]
+	return nil
+}
+
+// testServerDownload serves the content with the range support, `/plain` path serves it without.
+func testServerDownload(content []byte, etag string, ranges *[]string) *httptest.Server {
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		*ranges = append(*ranges, request.Header.Get("Range"))
+		mu.Unlock()
+		switch request.URL.Path {
+		case "/plain":
+			_, _ = writer.Write(content)
+		case "/missing":
+			http.NotFound(writer, request)
+		default:
+			writer.Header().Set("ETag", etag)
+			http.ServeContent(writer, request, "file.bin", time.Time{}, bytes.NewReader(content))
+		}
+	}))
+}
+
+func testContent(size int) ([]byte, string) {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	sum := sha256.Sum256(content)
+	return content, hex.EncodeToString(sum[:])
+}
+
+func TestClient_Download(t *testing.T) {
+	content, checksum := testContent(10000)
+	tests := []struct {
+		name       string
+		path       string
+		config     DownloadConfig
+		wantRanges []string
+		wantErr    error
+	}{
+		{
+			name:       "single",
+			path:       "/file",
+			config:     DownloadConfig{Checksum: checksum},
+			wantRanges: []string{"bytes=0-"},
+		},
+		{
+			name:       "segments",
+			path:       "/file",
+			config:     DownloadConfig{Segments: 4, MinSegmentSize: 2000, Checksum: strings.ToUpper(checksum)},
+			wantRanges: []string{"bytes=0-", "bytes=2500-4999", "bytes=5000-7499", "bytes=7500-9999"},
+		},
+		{
+			name:       "small segments",
+			path:       "/file",
+			config:     DownloadConfig{Segments: 10, MinSegmentSize: 4000},
+			wantRanges: []string{"bytes=0-", "bytes=5000-9999"},
+		},
+		{
+			name:       "no range support",
+			path:       "/plain",
+			config:     DownloadConfig{Segments: 4, MinSegmentSize: 1000, Checksum: checksum},
+			wantRanges: []string{"bytes=0-"},
+		},
+		{
+			name:       "checksum mismatch",
+			path:       "/file",
+			config:     DownloadConfig{Checksum: "00"},
+			wantRanges: []string{"bytes=0-"},
+			wantErr:    ErrChecksumMismatch,
+		},
+		{
+			name:       "not found",
+			path:       "/missing",
+			wantRanges: []string{"bytes=0-"},
+			wantErr:    ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ranges []string
+			server := testServerDownload(content, `"v1"`, &ranges)
+			defer server.Close()
+
+			var progress int64
+			tt.config.Progress = func(written int64, total int64) {
+				if written < progress {
+					t.Errorf("Progress() went back: %d < %d", written, progress)
+				}
+				progress = written
+			}
+			dst := new(testWriterAt)
+			err := NewClient(nil).Download(context.Background(), server.URL+tt.path, dst, tt.config)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("Download() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			server.Close()
+			sort.Strings(ranges)
+			if strings.Join(ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("Download() ranges = %q, want %q", ranges, tt.wantRanges)
+			}
+			if err == nil && (!bytes.Equal(dst.data, content) || progress != int64(len(content))) {
+				t.Errorf("Download() wrong content, progress = %d", progress)
+			}
+		})
+	}
+}
+
+func TestClient_Download_resume(t *testing.T) {
+	content, _ := testContent(10000)
+	var ranges []string
+	server := testServerDownload(content, `"v1"`, &ranges)
+	defer server.Close()
+
+	dst := &testWriterAt{data: append([]byte(nil), content[:3000]...)}
+	state := &DownloadState{ETag: `"v1"`, Size: 10000, Segments: []DownloadSegment{
+		{Start: 0, Length: 5000, Written: 3000},
+		{Start: 5000, Length: 5000, Written: 5000},
+	}}
+	_, _ = dst.WriteAt(content[5000:], 5000)
+	if err := NewClient(nil).Download(context.Background(), server.URL, dst, DownloadConfig{State: state}); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(dst.data, content) || strings.Join(ranges, ",") != "bytes=3000-4999" {
+		t.Errorf("Download() wrong content, ranges = %q", ranges)
+	}
+
+	// the resource was changed, it's downloaded again
+	ranges = nil
+	dst = &testWriterAt{data: bytes.Repeat([]byte{'x'}, 12000)}
+	state = &DownloadState{ETag: `"v0"`, Size: 12000, Segments: []DownloadSegment{{Start: 0, Length: 12000, Written: 6000}}}
+	if err := NewClient(nil).Download(context.Background(), server.URL, dst, DownloadConfig{State: state}); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(dst.data, content) || strings.Join(ranges, ",") != "bytes=6000-11999,bytes=0-" {
+		t.Errorf("Download() wrong content, ranges = %q", ranges)
+	}
+}
+
+func TestClient_DownloadFile(t *testing.T) {
+	content, checksum := testContent(200000)
+	var ranges []string
+	server := testServerDownload(content, `"v1"`, &ranges)
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "file.bin")
+	client := NewClient(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := client.DownloadFile(ctx, server.URL, path, DownloadConfig{
+		Progress: func(written int64, total int64) {
+			if written >= 100000 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+	if _, err = os.Stat(path + ".part.json"); err != nil {
+		t.Fatalf("DownloadFile() state is not saved: %v", err)
+	}
+
+	ranges = nil
+	if err = client.DownloadFile(context.Background(), server.URL, path, DownloadConfig{Checksum: checksum}); err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] == "bytes=0-" {
+		t.Errorf("DownloadFile() was not resumed, ranges = %q", ranges)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("DownloadFile() wrong content, error = %v", err)
+	}
+	for _, name := range []string{path + ".part", path + ".part.json"} {
+		if _, err = os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("DownloadFile() file %s is not removed: %v", name, err)
+		}
+	}
+}
+
+func TestClient_Download_retry(t *testing.T) {
+	content, _ := testContent(10000)
+	var (
+		mu       sync.Mutex
+		attempts []time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Range") == "bytes=0-" {
+			writer.Header().Set("Content-Range", "bytes 0-9999/10000")
+			writer.Header().Set("Content-Length", "10000")
+			writer.WriteHeader(http.StatusPartialContent)
+			_, _ = writer.Write(content[:5000])
+			return
+		}
+		mu.Lock()
+		attempts = append(attempts, time.Now())
+		failed := len(attempts) < 3
+		mu.Unlock()
+		if failed {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		http.ServeContent(writer, request, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dst := new(testWriterAt)
+	config := DownloadConfig{Retries: 3, RetryDelay: 20 * time.Millisecond}
+	if err := NewClient(nil).Download(context.Background(), server.URL, dst, config); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if !bytes.Equal(dst.data, content) || len(attempts) != 3 {
+		t.Fatalf("Download() wrong content, attempts = %d", len(attempts))
+	}
+	for i, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond} {
+		if delay := attempts[i+1].Sub(attempts[i]); delay < want {
+			t.Errorf("Download() retry #%d delay = %s, want at least %s", i+1, delay, want)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	attempts = nil
+	start := time.Now()
+	err := NewClient(nil).Download(ctx, server.URL, new(testWriterAt), DownloadConfig{Retries: 3, RetryDelay: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("Download() error = %v after %s", err, time.Since(start))
+	}
+}
+
+func TestClient_Download_contentRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Range", "bytes 100-199/200")
+		writer.WriteHeader(http.StatusPartialContent)
+		_, _ = writer.Write(make([]byte, 100))
+	}))
+	defer server.Close()
+
+	dst := new(testWriterAt)
+	err := NewClient(nil).Download(context.Background(), server.URL, dst, DownloadConfig{})
+	if err == nil || !strings.Contains(err.Error(), "unexpected Content-Range") || len(dst.data) != 0 {
+		t.Errorf("Download() error = %v, written = %d", err, len(dst.data))
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value             string
+		start, end, total int64
+		ok                bool
+	}{
+		{value: "bytes 0-99/1000", start: 0, end: 99, total: 1000, ok: true},
+		{value: "bytes 100-199/*", start: 100, end: 199, total: -1, ok: true},
+		{value: "bytes */1000"},
+		{value: "bytes 10-5/1000"},
+		{value: "items 0-1/2"},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		start, end, total, ok := parseContentRange(tt.value)
+		if start != tt.start || end != tt.end || total != tt.total || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %d, %v", tt.value, start, end, total, ok)
+		}
+	}
+}
@@ -1,29 +1,127 @@
 package chttp
 
 import (
-	"encoding/json"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	neturl "net/url"
+	"strings"
+	"syscall"
 )
 
 var (
 	ErrStatusCode = fmt.Errorf("wrong status code")
 	ErrUnknown    = fmt.Errorf("unknown error")
+	// ErrResponseTooLarge is returned if the response body exceeds the limit set by the WithMaxResponseSize option.
+	ErrResponseTooLarge = fmt.Errorf("response body too large")
+	// ErrTooManyPages is returned if the paged endpoint has more pages than the Pagination.MaxPages limit.
+	ErrTooManyPages = fmt.Errorf("too many pages")
+	// ErrSizeMismatch is returned if the size of the downloaded resource does not match the expected one.
+	ErrSizeMismatch = fmt.Errorf("size mismatch")
+	// ErrChecksumMismatch is returned if the checksum of the downloaded resource does not match the expected one.
+	ErrChecksumMismatch = fmt.Errorf("checksum mismatch")
+	// ErrMiddlewareNotFound is returned if there is no middleware with the given name.
+	ErrMiddlewareNotFound = fmt.Errorf("middleware not found")
+	// ErrMiddlewareExists is returned if the middleware with the given name is already added.
+	ErrMiddlewareExists = fmt.Errorf("middleware already exists")
 )
 
+// Sentinels of the error classes, which are matched by the *Error with errors.Is.
+var (
+	// ErrClientError matches the responses with the 4xx status codes.
+	ErrClientError = fmt.Errorf("client error")
+	// ErrServerError matches the responses with the 5xx status codes.
+	ErrServerError = fmt.Errorf("server error")
+	// ErrBadRequest matches the responses with the 400 status code.
+	ErrBadRequest = fmt.Errorf("bad request")
+	// ErrUnauthorized matches the responses with the 401 status code.
+	ErrUnauthorized = fmt.Errorf("unauthorized")
+	// ErrForbidden matches the responses with the 403 status code.
+	ErrForbidden = fmt.Errorf("forbidden")
+	// ErrNotFound matches the responses with the 404 status code.
+	ErrNotFound = fmt.Errorf("not found")
+	// ErrConflict matches the responses with the 409 status code.
+	ErrConflict = fmt.Errorf("conflict")
+	// ErrTooManyRequests matches the responses with the 429 status code.
+	ErrTooManyRequests = fmt.Errorf("too many requests")
+	// ErrTimeout matches the timeouts of the requests: context deadlines, network timeouts, and the responses
+	// with the 408 and 504 status codes.
+	ErrTimeout = fmt.Errorf("timeout")
+	// ErrCanceled matches the requests canceled with the context.
+	ErrCanceled = fmt.Errorf("canceled")
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:      ErrBadRequest,
+	http.StatusUnauthorized:    ErrUnauthorized,
+	http.StatusForbidden:       ErrForbidden,
+	http.StatusNotFound:        ErrNotFound,
+	http.StatusConflict:        ErrConflict,
+	http.StatusTooManyRequests: ErrTooManyRequests,
+}
+
+// sensitiveParams are the parts of the query parameter names, which values are redacted in the error messages.
+var sensitiveParams = [...]string{"token", "key", "secret", "password", "signature", "auth", "credential"}
+
 type Error struct {
 	Response *http.Response
 	Body     []byte
 	Base     error
+	// Problem is the problem details (RFC 9457) decoded from the `application/problem+json` response body.
+	Problem *Problem
+	// problemErr is the error constructed for the registered problem type.
+	problemErr error
+	// method and url are the request method and the redacted request url.
+	method string
+	url    string
+	// codec decodes the body in the Error.UnmarshalTo.
+	codec Codec
+}
+
+// NewError creates the Error of the response with the read body and the base error, the message is prefixed
+// with the request method and the redacted url, and the body is decoded with the JSONCodec in the Error.UnmarshalTo.
+func NewError(response *http.Response, body []byte, err error) *Error {
+	return newClientError(nil, nil, response, body, err)
 }
 
-func newError(response *http.Response, body []byte, err error) *Error {
-	return &Error{
+// newClientError creates the Error, which problem details are decoded with the JSONConfig and the problem types
+// of the client, and the body is decoded with the codec in the Error.UnmarshalTo, the JSONCodec by default.
+func newClientError(client *Client, codec Codec, response *http.Response, body []byte, err error) *Error {
+	var (
+		config       *JSONConfig
+		constructors map[string]func(problem *Problem) error
+	)
+	if client != nil {
+		config, constructors = client.jsonConfig, client.problems
+	}
+	if codec == nil {
+		codec = JSONCodec{Config: config}
+	}
+	result := &Error{
 		Response: response,
 		Body:     body,
 		Base:     err,
+		codec:    codec,
 	}
+	if err == nil {
+		result.Problem = parseProblem(config, response, body)
+		result.problemErr = problemError(result.Problem, constructors)
+	}
+	urlErr := new(neturl.Error)
+	if response != nil && response.Request != nil {
+		result.method, result.url = response.Request.Method, redactURL(response.Request.URL)
+	} else if errors.As(err, &urlErr) {
+		result.method = strings.ToUpper(urlErr.Op)
+		if uri, err := neturl.Parse(urlErr.URL); err == nil {
+			result.url = redactURL(uri)
+		}
+	}
+	return result
 }
 
 func (e *Error) IsStatusCode() bool {
@@ -39,14 +137,27 @@ func (e *Error) IsStatusCode() bool {
 	return false
 }
 
+// Error returns the error message prefixed with the request method and url. Password and the values
+// of the query parameters, which look sensitive, e.g. `token` or `api_key`, are redacted in the url.
 func (e *Error) Error() string {
 	if e == nil {
 		return ""
 	}
-	return e.Unwrap().Error()
+	message := e.Unwrap().Error()
+	if e.url == "" {
+		return message
+	}
+	urlErr := new(neturl.Error)
+	if errors.As(e.Base, &urlErr) {
+		message = strings.Replace(message, urlErr.Error(), urlErr.Err.Error(), 1)
+	}
+	return e.method + " " + e.url + ": " + message
 }
 
 func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
 	if e.Base != nil {
 		return e.Base
 	}
@@ -56,9 +167,157 @@ func (e *Error) Unwrap() error {
 	return ErrUnknown
 }
 
+// Is reports whether the error matches the target: the error class sentinel, like ErrNotFound, ErrServerError,
+// ErrTimeout, or ErrCanceled, or the error constructed for the registered problem type.
+func (e *Error) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	if e.problemErr != nil && errors.Is(e.problemErr, target) {
+		return true
+	}
+	switch target {
+	case ErrClientError:
+		return e.IsStatusCode() && e.Response.StatusCode >= 400 && e.Response.StatusCode < 500
+	case ErrServerError:
+		return e.IsStatusCode() && e.Response.StatusCode >= 500
+	case ErrTimeout:
+		return e.isTimeout()
+	case ErrCanceled:
+		return errors.Is(e.Base, context.Canceled)
+	}
+	return e.IsStatusCode() && statusErrors[e.Response.StatusCode] == target && target != nil
+}
+
+// Temporary reports whether the error is likely transient: timeouts, refused or reset connections, temporary DNS
+// failures, and the responses with the 408, 429, 502, 503, and 504 status codes.
+func (e *Error) Temporary() bool {
+	if e == nil {
+		return false
+	}
+	if e.IsStatusCode() {
+		switch e.Response.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return e.isTimeout() || isTemporaryTransport(e.Base)
+}
+
+// Retryable reports whether the request could be retried: the error is Temporary, or the response has
+// the 425 or 500 status code. Errors of the canceled or expired request context are not retryable, as well as
+// TLS certificate errors. Idempotency of the request method is not taken into account.
+func (e *Error) Retryable() bool {
+	if e == nil || errors.Is(e.Base, context.Canceled) || errors.Is(e.Base, context.DeadlineExceeded) {
+		return false
+	}
+	if e.IsStatusCode() && (e.Response.StatusCode == http.StatusTooEarly ||
+		e.Response.StatusCode == http.StatusInternalServerError) {
+		return true
+	}
+	return e.Temporary()
+}
+
+func (e *Error) isTimeout() bool {
+	if e.IsStatusCode() {
+		return e.Response.StatusCode == http.StatusRequestTimeout || e.Response.StatusCode == http.StatusGatewayTimeout
+	}
+	if errors.Is(e.Base, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(e.Base, &netErr) && netErr.Timeout()
+}
+
+// isTemporaryTransport reports whether the transport error is transient.
+func isTemporaryTransport(err error) bool {
+	if err == nil || isCertificateError(err) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	for _, target := range [...]error{
+		syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE,
+		io.EOF, io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCertificateError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		recordHeader     tls.RecordHeaderError
+	)
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) ||
+		errors.As(err, &recordHeader)
+}
+
+// redactURL removes the password and the values of the sensitive query parameters from the url.
+func redactURL(uri *neturl.URL) string {
+	if uri == nil {
+		return ""
+	}
+	if uri.RawQuery == "" {
+		return uri.Redacted()
+	}
+	query := uri.Query()
+	redacted := false
+	for name, values := range query {
+		if !isSensitive(name) {
+			continue
+		}
+		for i := range values {
+			values[i] = "xxxxx"
+		}
+		redacted = true
+	}
+	if !redacted {
+		return uri.Redacted()
+	}
+	clone := *uri
+	clone.RawQuery = query.Encode()
+	return clone.Redacted()
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveParams {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in the chain of the error constructed for the registered problem type
+// or the Problem, that matches the target.
+func (e *Error) As(target interface{}) bool {
+	if e == nil {
+		return false
+	}
+	if e.problemErr != nil && errors.As(e.problemErr, target) {
+		return true
+	}
+	if problem, ok := target.(**Problem); ok && e.Problem != nil {
+		*problem = e.Problem
+		return true
+	}
+	return false
+}
+
 func (e *Error) UnmarshalTo(value interface{}) (bool, error) {
 	if e.IsStatusCode() {
-		err := json.Unmarshal(e.Body, &value)
+		err := unmarshal(e.codec, e.Body, value)
 		if err != nil {
 			return false, err
 		}
@@ -2,10 +2,18 @@ package chttp
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	neturl "net/url"
+	"os"
 	"reflect"
+	"syscall"
 	"testing"
 )
 
@@ -47,3 +55,156 @@ func TestErrorUnmarshalTo(t *testing.T) {
 		t.Errorf("ErrorUnmarshalTo() = %v, want %v", result, expected)
 	}
 }
+
+func TestError_Is(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/pets", nil)
+	status := func(code int) *Error {
+		return NewError(&http.Response{StatusCode: code, Request: request}, nil, nil)
+	}
+	tests := []struct {
+		name      string
+		err       *Error
+		is        []error
+		isNot     []error
+		temporary bool
+		retryable bool
+	}{
+		{
+			name:  "not found",
+			err:   status(http.StatusNotFound),
+			is:    []error{ErrStatusCode, ErrNotFound, ErrClientError},
+			isNot: []error{ErrServerError, ErrUnauthorized, ErrTimeout, ErrCanceled},
+		},
+		{
+			name:  "unauthorized",
+			err:   status(http.StatusUnauthorized),
+			is:    []error{ErrUnauthorized, ErrClientError},
+			isNot: []error{ErrNotFound, ErrServerError},
+		},
+		{
+			name:      "too many requests",
+			err:       status(http.StatusTooManyRequests),
+			is:        []error{ErrTooManyRequests, ErrClientError},
+			temporary: true,
+			retryable: true,
+		},
+		{
+			name:      "internal server error",
+			err:       status(http.StatusInternalServerError),
+			is:        []error{ErrServerError},
+			isNot:     []error{ErrClientError, ErrTimeout},
+			retryable: true,
+		},
+		{
+			name:      "gateway timeout",
+			err:       status(http.StatusGatewayTimeout),
+			is:        []error{ErrServerError, ErrTimeout},
+			temporary: true,
+			retryable: true,
+		},
+		{
+			name:  "not implemented",
+			err:   status(http.StatusNotImplemented),
+			is:    []error{ErrServerError},
+			isNot: []error{ErrTimeout},
+		},
+		{
+			name:      "deadline",
+			err:       NewError(nil, nil, fmt.Errorf("requesting error: %w", context.DeadlineExceeded)),
+			is:        []error{ErrTimeout, context.DeadlineExceeded},
+			isNot:     []error{ErrCanceled, ErrStatusCode},
+			temporary: true,
+		},
+		{
+			name:  "canceled",
+			err:   NewError(nil, nil, fmt.Errorf("requesting error: %w", context.Canceled)),
+			is:    []error{ErrCanceled},
+			isNot: []error{ErrTimeout},
+		},
+		{
+			name:      "connection refused",
+			err:       NewError(nil, nil, &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}),
+			temporary: true,
+			retryable: true,
+		},
+		{
+			name:      "network timeout",
+			err:       NewError(nil, nil, &net.DNSError{Err: "timeout", IsTimeout: true}),
+			is:        []error{ErrTimeout},
+			temporary: true,
+			retryable: true,
+		},
+		{
+			name: "no such host",
+			err:  NewError(nil, nil, &net.DNSError{Err: "no such host", IsNotFound: true}),
+		},
+		{
+			name: "certificate",
+			err:  NewError(nil, nil, &neturl.Error{Op: "Get", URL: "https://example.com", Err: x509.UnknownAuthorityError{}}),
+		},
+		{
+			name:  "marshaling",
+			err:   NewError(nil, nil, fmt.Errorf("marshaling request error: %w", errors.New("unsupported type"))),
+			isNot: []error{ErrTimeout, ErrCanceled, ErrClientError, ErrServerError, ErrNotFound},
+		},
+		{
+			name:  "nil",
+			err:   nil,
+			isNot: []error{ErrStatusCode, ErrNotFound, ErrClientError, ErrTimeout, ErrCanceled, ErrUnknown},
+		},
+		{
+			name:      "connection closed",
+			err:       NewError(nil, nil, fmt.Errorf("requesting error: %w", io.ErrUnexpectedEOF)),
+			temporary: true,
+			retryable: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, target := range tt.is {
+				if !errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%v, %v) = false", tt.err, target)
+				}
+			}
+			for _, target := range tt.isNot {
+				if errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%v, %v) = true", tt.err, target)
+				}
+			}
+			if got := tt.err.Temporary(); got != tt.temporary {
+				t.Errorf("Temporary() = %v, want %v", got, tt.temporary)
+			}
+			if got := tt.err.Retryable(); got != tt.retryable {
+				t.Errorf("Retryable() = %v, want %v", got, tt.retryable)
+			}
+			if problem := new(Problem); tt.err == nil && errors.As(tt.err, &problem) {
+				t.Errorf("errors.As(%v) = true", tt.err)
+			}
+		})
+	}
+}
+
+func TestError_Error_request(t *testing.T) {
+	server := testServerJSON(http.StatusBadGateway, 123)
+	uri, _ := neturl.Parse(server.URL)
+	uri.User = neturl.UserPassword("user", "secret")
+	server.Close()
+
+	client := NewJSON(nil)
+	err := client.GET(context.Background(), uri.String()+"/pets?limit=10&access_token=abc", nil, nil)
+	want := "GET http://user:xxxxx@" + uri.Host + "/pets?access_token=xxxxx&limit=10: requesting error: dial tcp " +
+		uri.Host + ": connect: connection refused"
+	if err == nil || err.Error() != want {
+		t.Errorf("GET() error = %v, want %v", err, want)
+	}
+	if cErr := new(Error); !errors.As(err, &cErr) || !cErr.Retryable() {
+		t.Errorf("GET() error is not retryable: %v", err)
+	}
+
+	server = testServerJSON(http.StatusBadGateway, 123)
+	defer server.Close()
+	err = client.POST(context.Background(), server.URL+"/pets?limit=10", nil, nil)
+	if want = "POST " + server.URL + "/pets?limit=10: wrong status code, status_code=502"; err == nil || err.Error() != want {
+		t.Errorf("POST() error = %v, want %v", err, want)
+	}
+}
@@ -18,11 +18,9 @@ func NewClient(host string, apiKey string) *Client {
 	}
 }
 
-func url(host, path string, query map[string][]string) (string, error) {
-	uri, err := ulrp.Parse(host + path)
-	if err != nil {
-		return "", err
+func url(path string, query map[string][]string) string {
+	if len(query) == 0 {
+		return path
 	}
-	uri.RawQuery = ulrp.Values(query).Encode()
-	return uri.String(), nil
+	return path + "?" + ulrp.Values(query).Encode()
 }
@@ -12,113 +12,89 @@ import (
 
 // PetClient provides everything about your Pets
 type PetClient struct {
-	path   string
 	client *chttp.JSONClient
+	form   *chttp.FormClient
 }
 
 func NewPetClient(host string, apiKey string) *PetClient {
-	client := chttp.NewJSON(&http.Client{Timeout: 10 * time.Second})
+	client := chttp.NewJSON(&http.Client{Timeout: 10 * time.Second}, chttp.WithBaseURL(host))
 	client.With(middleware.JSON())
 	client.With(middleware.Headers(map[string]string{
 		"api_key": apiKey,
 	}, true))
 
 	return &PetClient{
-		path:   host + "/pet",
 		client: client,
+		form:   client.Form(),
 	}
 }
 
 // Update calls HTTP PUT /pet Update an existing pet
 func (c *PetClient) Update(ctx context.Context, pet Pet) (result Pet, err error) {
-	uri, err := url(c.path, "", nil)
-	if err != nil {
-		return result, err
-	}
-	err = c.client.PUT(ctx, uri, pet, &result)
+	err = c.client.PUT(ctx, "/pet", pet, &result)
 	return result, err
 }
 
 // Add calls HTTP POST /pet Update an existing pet
 func (c *PetClient) Add(ctx context.Context, pet Pet) (result Pet, err error) {
-	uri, err := url(c.path, "", nil)
-	if err != nil {
-		return result, err
-	}
-	err = c.client.POST(ctx, uri, pet, &result)
+	err = c.client.POST(ctx, "/pet", pet, &result)
 	return result, err
 }
 
+type findByStatusRequest struct {
+	Status string `query:"status"`
+}
+
 // FindByStatus calls HTTP POST /pet/findByStatus Finds Pets by status
 func (c *PetClient) FindByStatus(ctx context.Context, status string) (result Pets, err error) {
-	uri, err := url(c.path, "/findByStatus", map[string][]string{"status": {status}})
-	if err != nil {
-		return result, err
-	}
-	err = c.client.GET(ctx, uri, nil, &result)
+	err = c.client.GET(ctx, "/pet/findByStatus", findByStatusRequest{Status: status}, &result)
 	return result, err
 }
 
+type findByTagsRequest struct {
+	Tags []string `query:"tags[]"`
+}
+
 // FindByTags calls HTTP POST /pet/findByTags Finds Pets by tags
 func (c *PetClient) FindByTags(ctx context.Context, tags []string) (result Pets, err error) {
-	uri, err := url(c.path, "/findByTags", map[string][]string{"tags[]": tags})
-	if err != nil {
-		return result, err
-	}
-	err = c.client.GET(ctx, uri, nil, &result)
+	err = c.client.GET(ctx, "/pet/findByTags", findByTagsRequest{Tags: tags}, &result)
 	return result, err
 }
 
+type petRequest struct {
+	PetID string `path:"petId"`
+}
+
 // Find calls HTTP GET /pet/{petId} Find pet by ID
 func (c *PetClient) Find(ctx context.Context, petId string) (result Pet, err error) {
-	uri, err := url(c.path, "/"+petId, nil)
-	if err != nil {
-		return result, err
-	}
-	err = c.client.GET(ctx, uri, nil, &result)
+	err = c.client.GET(ctx, "/pet/{petId}", petRequest{PetID: petId}, &result)
 	return result, err
 }
 
+type updateByIDRequest struct {
+	PetID  string  `path:"petId"`
+	Name   *string `form:"name"`
+	Status *string `form:"status"`
+}
+
 // UpdateByID calls HTTP /pet/{petId} Updates a pet in the store with form data
 func (c *PetClient) UpdateByID(ctx context.Context, petId string, name *string, status *string) (result Pet, err error) {
-	params := make(map[string][]string)
-	if name != nil {
-		params["name"] = []string{*name}
-	}
-	if status != nil {
-		params["status"] = []string{*status}
-	}
-	uri, err := url(c.path, "/"+petId, params)
-	if err != nil {
-		return result, err
-	}
-	err = c.client.POST(ctx, uri, nil, &result)
+	err = c.form.POST(ctx, "/pet/{petId}", updateByIDRequest{PetID: petId, Name: name, Status: status}, &result)
 	return result, err
 }
 
 // Delete calls HTTP DELETE /pet/{petId} Deletes a pet
 func (c *PetClient) Delete(ctx context.Context, petId string) (err error) {
-	uri, err := url(c.path, "/"+petId, nil)
-	if err != nil {
-		return err
-	}
-	err = c.client.DELETE(ctx, uri, nil, nil)
+	err = c.client.DELETE(ctx, "/pet/{petId}", petRequest{PetID: petId}, nil)
 	return err
 }
 
 // UploadImage calls HTTP POST /pet/{petId}/uploadImage uploads an image
 func (c *PetClient) UploadImage(ctx context.Context, petId string, image io.Reader) (result ApiResponse, err error) {
-	uri, err := url(c.path, "/"+petId+"/uploadImage", nil)
-	if err != nil {
-		return result, err
-	}
-
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, image)
-	request.Header.Set("Content-Type", "application/octet-stream")
-	response, err := c.client.Do(request)
-	if err != nil {
-		return result, err
-	}
-	err = c.client.UnmarshalHTTPResponse(response, err, &result)
+	err = c.client.NewRequest(http.MethodPost, "/pet/{petId}/uploadImage").
+		Param("petId", petId).
+		Header("Content-Type", "application/octet-stream").
+		BodyReader(image).
+		Into(ctx, &result)
 	return result, err
 }
@@ -0,0 +1,231 @@
+package chttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"reflect"
+	"strings"
+)
+
+// FormClient is an HTTP client wrapper around the Client
+// with automated encoding of the request body as a form and unmarshaling JSON response body.
+//
+// The request body could be one of: url.Values, map[string][]string, map[string]string, a structure,
+// or *Multipart for the `multipart/form-data` requests. Fields of the structure are encoded with the `form` tag,
+// e.g. `form:"name,omitempty"`, fields with `path`, `query`, and `header` tags are lifted as in the JSONClient.
+// Forms of the GET and HEAD requests are encoded into the query.
+type FormClient struct {
+	*Client
+}
+
+// Form wraps Client with the FormClient.
+func Form(client *Client) *FormClient {
+	return &FormClient{
+		Client: client,
+	}
+}
+
+// NewForm creates a FormClient with new Client based on given http.Client.
+func NewForm(client *http.Client, options ...Option) *FormClient {
+	return &FormClient{
+		Client: NewClient(client, options...),
+	}
+}
+
+// Form creates a FormClient wrapper with the given Client as a basic one.
+func (c *Client) Form() *FormClient {
+	return Form(c)
+}
+
+// Request prepares the request by encoding request body as a form and tries to unmarshal response
+// with the FormClient.UnmarshalHTTPResponse function.
+func (c *FormClient) Request(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+	result interface{},
+) (err error) {
+	reader, params, err := encodeForm(method, body)
+	if err != nil {
+		return err
+	}
+	res, err := c.Client.request(ctx, method, url, reader, params)
+	return c.UnmarshalHTTPResponse(res, err, &result)
+}
+
+// UnmarshalHTTPResponse tries to unmarshal the response body into the given result interface.
+// Result should be reference type and not nil.
+func (c *FormClient) UnmarshalHTTPResponse(response *http.Response, httpErr error, result interface{}) (err error) {
+	return c.Client.unmarshalHTTPResponse(response, httpErr, result, nil)
+}
+
+// Method returns a function implementation of the HTTP Method from the Client by its name.
+// Returns Client.GET as the default method.
+func (c *FormClient) Method(
+	method string,
+) func(ctx context.Context, url string, body interface{}, result interface{}) error {
+	switch method {
+	case http.MethodHead:
+		return c.HEAD
+	case http.MethodPost:
+		return c.POST
+	case http.MethodPut:
+		return c.PUT
+	case http.MethodPatch:
+		return c.PATCH
+	case http.MethodDelete:
+		return c.DELETE
+	case http.MethodConnect:
+		return c.CONNECT
+	case http.MethodOptions:
+		return c.OPTIONS
+	case http.MethodTrace:
+		return c.TRACE
+	case http.MethodGet:
+		fallthrough
+	default:
+		return c.GET
+	}
+}
+
+// GET is an alias to do the Request with the http.MethodGet method.
+func (c *FormClient) GET(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodGet, url, body, &result)
+}
+
+// HEAD is an alias to do the Request with the http.MethodHead method.
+func (c *FormClient) HEAD(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodHead, url, body, &result)
+}
+
+// POST is an alias to do the Request with the http.MethodPost method.
+func (c *FormClient) POST(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPost, url, body, &result)
+}
+
+// PUT is an alias to do the Request with the http.MethodPut method.
+func (c *FormClient) PUT(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPut, url, body, &result)
+}
+
+// PATCH is an alias to do the Request with the http.MethodPatch method.
+func (c *FormClient) PATCH(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodPatch, url, body, &result)
+}
+
+// DELETE is an alias to do the Request with the http.MethodDelete method.
+func (c *FormClient) DELETE(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodDelete, url, body, &result)
+}
+
+// CONNECT is an alias to do the Request with the http.MethodConnect method.
+func (c *FormClient) CONNECT(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodConnect, url, body, &result)
+}
+
+// OPTIONS is an alias to do the Request with the http.MethodOptions method.
+func (c *FormClient) OPTIONS(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodOptions, url, body, &result)
+}
+
+// TRACE is an alias to do the Request with the http.MethodTrace method.
+func (c *FormClient) TRACE(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.Request(ctx, http.MethodTrace, url, body, &result)
+}
+
+// Clone will clone an instance of the FormClient without references to the old one.
+func (c *FormClient) Clone() *FormClient {
+	return Form(c.Client.Clone())
+}
+
+// encodeForm encodes the body into the request body reader and the list of the request parameters.
+func encodeForm(method string, body interface{}) (io.Reader, *requestParams, error) {
+	params := newRequestParams()
+	if multipart, ok := body.(*Multipart); ok {
+		reader, contentType := multipart.reader()
+		params.header.Set("Content-Type", contentType)
+		return reader, params, nil
+	}
+	values, err := formValues(body, params)
+	if err != nil {
+		return nil, nil, NewError(nil, nil, fmt.Errorf("encoding form error: %w", err))
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		for name, list := range values {
+			params.query[name] = append(params.query[name], list...)
+		}
+		return nil, params, nil
+	}
+	params.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return strings.NewReader(values.Encode()), params, nil
+}
+
+func formValues(body interface{}, params *requestParams) (neturl.Values, error) {
+	switch body := body.(type) {
+	case nil:
+		return neturl.Values{}, nil
+	case neturl.Values:
+		return body, nil
+	case map[string][]string:
+		return body, nil
+	case map[string]string:
+		values := make(neturl.Values, len(body))
+		for name, value := range body {
+			values.Set(name, value)
+		}
+		return values, nil
+	}
+	value := reflect.ValueOf(body)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported form type: %T", body)
+	}
+	if _, _, err := params.collect(value); err != nil {
+		return nil, err
+	}
+	values := make(neturl.Values)
+	return values, collectForm(value, values)
+}
+
+func collectForm(value reflect.Value, values neturl.Values) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if kind, _, _ := paramTag(field.Tag); kind != "" {
+			continue
+		}
+		name, options := parseTag(field.Tag.Get("form"))
+		if name == "-" {
+			continue
+		}
+		if embedded, ok := embeddedStruct(field, value.Field(i)); ok && name == "" {
+			if err := collectForm(embedded, values); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		list, ok, err := encodeParam(value.Field(i), field.Tag.Get("layout"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if !ok || (options["omitempty"] && isEmptyParam(value.Field(i))) {
+			continue
+		}
+		if options["comma"] {
+			list = []string{strings.Join(list, ",")}
+		}
+		values[name] = append(values[name], list...)
+	}
+	return nil
+}
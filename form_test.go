@@ -0,0 +1,121 @@
+package chttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFormClient_Method(t *testing.T) {
+	type request struct {
+		PetID  string   `path:"petId" json:"-"`
+		Name   string   `form:"name"`
+		Status *string  `form:"status,omitempty"`
+		Tags   []string `form:"tags"`
+		Skip   string   `form:"-"`
+		Token  string   `header:"X-Token"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/pet/10" {
+			t.Errorf("wrong path: %s", request.URL.Path)
+		}
+		if request.Header.Get("X-Token") != "token" {
+			t.Errorf("wrong header: %v", request.Header)
+		}
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			if request.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				t.Errorf("wrong content type: %s", request.Header.Get("Content-Type"))
+			}
+			if len(request.URL.Query()) != 0 {
+				t.Errorf("unexpected query: %v", request.URL.Query())
+			}
+		}
+		if err := request.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		writer.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(writer).Encode(request.Form)
+	}))
+	defer server.Close()
+
+	want := url.Values{
+		"name": {"doggie"},
+		"tags": {"a", "b"},
+	}
+	methods := []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+	}
+	client := NewForm(nil)
+	for _, method := range methods {
+		t.Run(method, func(t *testing.T) {
+			var result url.Values
+			err := client.Method(method)(context.Background(), server.URL+"/pet/{petId}", &request{
+				PetID: "10",
+				Name:  "doggie",
+				Tags:  []string{"a", "b"},
+				Skip:  "skip",
+				Token: "token",
+			}, &result)
+			if err != nil {
+				t.Errorf("%s() error = %v", method, err)
+				return
+			}
+			if !reflect.DeepEqual(result, want) {
+				t.Errorf("%s() = %v, want %v", method, result, want)
+			}
+		})
+	}
+}
+
+func TestFormClient_Request_maps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_ = request.ParseForm()
+		writer.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(writer).Encode(request.PostForm)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(nil).Form()
+	tests := []struct {
+		name string
+		body interface{}
+		want url.Values
+	}{
+		{name: "url.Values", body: url.Values{"a": {"1", "2"}}, want: url.Values{"a": {"1", "2"}}},
+		{name: "map[string][]string", body: map[string][]string{"a": {"1"}}, want: url.Values{"a": {"1"}}},
+		{name: "map[string]string", body: map[string]string{"a": "1"}, want: url.Values{"a": {"1"}}},
+		{name: "nil", body: nil, want: url.Values{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result url.Values
+			if err := client.Clone().POST(ctx, server.URL, tt.body, &result); err != nil {
+				t.Errorf("POST() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(result, tt.want) {
+				t.Errorf("POST() = %v, want %v", result, tt.want)
+			}
+		})
+	}
+
+	if err := client.POST(ctx, server.URL, 123, nil); err == nil {
+		t.Errorf("POST() error wanted")
+	}
+}
+
+func mustMarshal(t *testing.T, value interface{}) []byte {
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Errorf("json.Marshal() error = %v", err)
+	}
+	return data
+}
@@ -3,6 +3,7 @@ package chttp
 import (
 	"context"
 	"net/http"
+	"time"
 )
 
 // GenericJSONClient is an HTTP client wrapper around the Client
@@ -33,6 +34,58 @@ func (c *GenericJSONClient[Result]) Request(
 	return result, err
 }
 
+// StreamRequest is the same as the GenericJSONClient.Request, but the request body is streamed
+// with the json.Encoder through the io.Pipe without buffering it in memory.
+func (c *GenericJSONClient[Result]) StreamRequest(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+) (result Result, err error) {
+	err = c.JSONClient.StreamRequest(ctx, method, url, body, &result)
+	return result, err
+}
+
+// Fetch is the same as the GenericJSONClient.Request, but returns the decoded value together with
+// the response metadata: status code, headers, trailers, duration, and the final url after the redirects.
+// The Response is returned with the error as well, if the response was received.
+//
+//	response, err := client.Fetch(ctx, http.MethodPost, "/pets", pet)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(response.Header.Get("Location"), response.Value.ID)
+func (c *GenericJSONClient[Result]) Fetch(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+) (*Response[Result], error) {
+	reader, params, err := c.prepare(body)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	var result Result
+	response, err := c.Client.request(ctx, method, url, reader, params)
+	err = c.JSONClient.UnmarshalHTTPResponse(response, err, &result)
+	if response == nil {
+		return nil, err
+	}
+	return newResponse(result, response, start), err
+}
+
+// Stream sends the request and returns the Stream over the JSON records of the response body.
+// See the StreamJSON function for the details.
+func (c *GenericJSONClient[Result]) Stream(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+) *Stream[Result] {
+	return StreamJSON[Result](ctx, &c.JSONClient, method, url, body)
+}
+
 // UnmarshalHTTPResponse tries to unmarshal the response body into the given result interface.
 // Result should be reference type and not nil.
 func (c *GenericJSONClient[Result]) UnmarshalHTTPResponse(
@@ -96,6 +149,21 @@ func (c *GenericJSONClient[Result]) PATCH(ctx context.Context, url string, body
 	return c.Request(ctx, http.MethodPatch, url, body)
 }
 
+// StreamPOST is an alias to do the StreamRequest with the http.MethodPost method.
+func (c *GenericJSONClient[Result]) StreamPOST(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.StreamRequest(ctx, http.MethodPost, url, body)
+}
+
+// StreamPUT is an alias to do the StreamRequest with the http.MethodPut method.
+func (c *GenericJSONClient[Result]) StreamPUT(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.StreamRequest(ctx, http.MethodPut, url, body)
+}
+
+// StreamPATCH is an alias to do the StreamRequest with the http.MethodPatch method.
+func (c *GenericJSONClient[Result]) StreamPATCH(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.StreamRequest(ctx, http.MethodPatch, url, body)
+}
+
 // DELETE is an alias to do the Request with the http.MethodDelete method.
 func (c *GenericJSONClient[Result]) DELETE(ctx context.Context, url string, body interface{}) (Result, error) {
 	return c.Request(ctx, http.MethodDelete, url, body)
@@ -0,0 +1,121 @@
+package chttp
+
+import (
+	"context"
+	"net/http"
+)
+
+// GenericCodecClient is an HTTP client wrapper around the CodecClient
+// with automated encoding of the request body and decoding response body into a known structure.
+type GenericCodecClient[Result any] struct {
+	CodecClient
+}
+
+// NewGenericCodecClient wraps CodecClient with the GenericCodecClient.
+func NewGenericCodecClient[Result any](codec *CodecClient) *GenericCodecClient[Result] {
+	if codec == nil {
+		codec = NewCodecClient(nil, nil)
+	}
+	return &GenericCodecClient[Result]{
+		CodecClient: *codec,
+	}
+}
+
+// Request prepares the request by encoding request body and tries to decode response
+// with the CodecClient.UnmarshalHTTPResponse function.
+func (c *GenericCodecClient[Result]) Request(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+) (result Result, err error) {
+	err = c.CodecClient.Request(ctx, method, url, body, &result)
+	return result, err
+}
+
+// UnmarshalHTTPResponse tries to decode the response body into the Result.
+func (c *GenericCodecClient[Result]) UnmarshalHTTPResponse(
+	response *http.Response,
+	httpErr error,
+) (result Result, err error) {
+	return result, c.CodecClient.UnmarshalHTTPResponse(response, httpErr, &result)
+}
+
+// Method returns a function implementation of the HTTP Method from the Client by its name.
+// Returns Client.GET as the default method.
+func (c *GenericCodecClient[Result]) Method(
+	method string,
+) func(ctx context.Context, url string, body interface{}) (Result, error) {
+	switch method {
+	case http.MethodHead:
+		return c.HEAD
+	case http.MethodPost:
+		return c.POST
+	case http.MethodPut:
+		return c.PUT
+	case http.MethodPatch:
+		return c.PATCH
+	case http.MethodDelete:
+		return c.DELETE
+	case http.MethodConnect:
+		return c.CONNECT
+	case http.MethodOptions:
+		return c.OPTIONS
+	case http.MethodTrace:
+		return c.TRACE
+	case http.MethodGet:
+		fallthrough
+	default:
+		return c.GET
+	}
+}
+
+// GET is an alias to do the Request with the http.MethodGet method.
+func (c *GenericCodecClient[Result]) GET(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodGet, url, body)
+}
+
+// HEAD is an alias to do the Request with the http.MethodHead method.
+func (c *GenericCodecClient[Result]) HEAD(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodHead, url, body)
+}
+
+// POST is an alias to do the Request with the http.MethodPost method.
+func (c *GenericCodecClient[Result]) POST(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodPost, url, body)
+}
+
+// PUT is an alias to do the Request with the http.MethodPut method.
+func (c *GenericCodecClient[Result]) PUT(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodPut, url, body)
+}
+
+// PATCH is an alias to do the Request with the http.MethodPatch method.
+func (c *GenericCodecClient[Result]) PATCH(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodPatch, url, body)
+}
+
+// DELETE is an alias to do the Request with the http.MethodDelete method.
+func (c *GenericCodecClient[Result]) DELETE(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodDelete, url, body)
+}
+
+// CONNECT is an alias to do the Request with the http.MethodConnect method.
+func (c *GenericCodecClient[Result]) CONNECT(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodConnect, url, body)
+}
+
+// OPTIONS is an alias to do the Request with the http.MethodOptions method.
+func (c *GenericCodecClient[Result]) OPTIONS(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodOptions, url, body)
+}
+
+// TRACE is an alias to do the Request with the http.MethodTrace method.
+func (c *GenericCodecClient[Result]) TRACE(ctx context.Context, url string, body interface{}) (Result, error) {
+	return c.Request(ctx, http.MethodTrace, url, body)
+}
+
+// Clone will clone an instance of the GenericCodecClient without references to the old one.
+func (c *GenericCodecClient[Result]) Clone() *GenericCodecClient[Result] {
+	return NewGenericCodecClient[Result](c.CodecClient.Clone())
+}
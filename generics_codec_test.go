@@ -0,0 +1,43 @@
+package chttp
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenericCodecClient_Method(t *testing.T) {
+	type example struct {
+		XMLName xml.Name `xml:"example"`
+		Method  string   `xml:"method"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/xml")
+		_ = xml.NewEncoder(writer).Encode(example{Method: request.Method})
+	}))
+	defer server.Close()
+	client := NewGenericCodecClient[example](NewCodecClient(nil, []Codec{JSONCodec{}, XMLCodec{}}))
+
+	for _, method := range []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodConnect,
+		http.MethodOptions,
+		http.MethodTrace,
+	} {
+		t.Run(method, func(t *testing.T) {
+			got, err := client.Method(method)(context.Background(), server.URL, nil)
+			if err != nil || got.Method != method {
+				t.Errorf("Method() got = %v, error = %v", got, err)
+			}
+		})
+	}
+	if clone := client.Clone(); clone.Client == client.Client {
+		t.Errorf("Clone() returned the same Client")
+	}
+}
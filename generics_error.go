@@ -0,0 +1,72 @@
+package chttp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ResponseError is an error of the unsuccessful response with the body decoded into the ErrorBody.
+// Use errors.As to get it from the error returned by the GenericJSONErrorClient:
+//
+//	apiErr := new(chttp.ResponseError[APIError])
+//	if errors.As(err, &apiErr) {
+//		fmt.Println(apiErr.StatusCode, apiErr.Value.Message)
+//	}
+type ResponseError[ErrorBody any] struct {
+	// Value is the decoded response body.
+	Value ErrorBody
+	// StatusCode is the response status code.
+	StatusCode int
+	// Response is the response with the already closed body.
+	Response *http.Response
+	// Err is the original error with the raw response body.
+	Err *Error
+}
+
+func (e *ResponseError[ErrorBody]) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResponseError[ErrorBody]) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError decodes the body of the unsuccessful response error into the ErrorBody with the Error.UnmarshalTo
+// and returns the *ResponseError. Other errors, and errors with the body that could not be decoded, are returned as is.
+func DecodeError[ErrorBody any](err error) error {
+	base := new(Error)
+	if !errors.As(err, &base) || !base.IsStatusCode() {
+		return err
+	}
+	var value ErrorBody
+	if _, decodeErr := base.UnmarshalTo(&value); decodeErr != nil {
+		return err
+	}
+	return &ResponseError[ErrorBody]{
+		Value:      value,
+		StatusCode: base.Response.StatusCode,
+		Response:   base.Response,
+		Err:        base,
+	}
+}
+
+// GenericJSONErrorClient is the GenericJSONClient, which decodes the bodies of the unsuccessful responses
+// into the ErrorBody and returns them as the *ResponseError. All methods of the GenericJSONClient, which decode
+// the response, return the *ResponseError.
+type GenericJSONErrorClient[Result any, ErrorBody any] struct {
+	GenericJSONClient[Result]
+}
+
+// NewGenericJSONErrorClient wraps JSONClient with the GenericJSONErrorClient.
+func NewGenericJSONErrorClient[Result any, ErrorBody any](json *JSONClient) *GenericJSONErrorClient[Result, ErrorBody] {
+	client := &GenericJSONErrorClient[Result, ErrorBody]{
+		GenericJSONClient: *NewGenericJSONClient[Result](json),
+	}
+	client.decodeError = DecodeError[ErrorBody]
+	return client
+}
+
+// Clone will clone an instance of the GenericJSONErrorClient without references to the old one.
+func (c *GenericJSONErrorClient[Result, ErrorBody]) Clone() *GenericJSONErrorClient[Result, ErrorBody] {
+	return NewGenericJSONErrorClient[Result, ErrorBody](JSON(c.Client.Clone()))
+}
@@ -0,0 +1,135 @@
+package chttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testAPIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func TestGenericJSONErrorClient_Method(t *testing.T) {
+	type example struct {
+		Method string `json:"method"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/fail" {
+			writer.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(writer).Encode(testAPIError{Code: "conflict", Message: request.Method})
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(example{Method: request.Method})
+	}))
+	defer server.Close()
+	client := NewGenericJSONErrorClient[example, testAPIError](nil)
+
+	for _, method := range []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodConnect,
+		http.MethodOptions,
+		http.MethodTrace,
+	} {
+		t.Run(method, func(t *testing.T) {
+			got, err := client.Method(method)(context.Background(), server.URL, nil)
+			if err != nil || got.Method != method {
+				t.Errorf("Method() got = %v, error = %v", got, err)
+			}
+
+			_, err = client.Method(method)(context.Background(), server.URL+"/fail", nil)
+			apiErr := new(ResponseError[testAPIError])
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Method() wrong error = %v", err)
+			}
+			if apiErr.StatusCode != http.StatusConflict || apiErr.Value.Code != "conflict" || apiErr.Value.Message != method {
+				t.Errorf("Method() wrong error value = %+v", apiErr)
+			}
+			if !errors.Is(err, ErrStatusCode) {
+				t.Errorf("Method() error does not wrap the ErrStatusCode: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantTyped bool
+	}{
+		{name: "nil", err: nil},
+		{name: "other", err: ErrUnknown},
+		{name: "request error", err: NewError(nil, nil, ErrUnknown)},
+		{
+			name: "invalid body",
+			err:  NewError(&http.Response{StatusCode: http.StatusBadGateway}, []byte("<html>"), nil),
+		},
+		{
+			name:      "typed",
+			err:       NewError(&http.Response{StatusCode: http.StatusBadRequest}, []byte(`{"code":"bad"}`), nil),
+			wantTyped: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeError[testAPIError](tt.err)
+			apiErr := new(ResponseError[testAPIError])
+			if errors.As(err, &apiErr) != tt.wantTyped {
+				t.Errorf("DecodeError() = %v, wantTyped %v", err, tt.wantTyped)
+			}
+			if !tt.wantTyped && err != tt.err {
+				t.Errorf("DecodeError() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDecodeError_config(t *testing.T) {
+	type xmlError struct {
+		Code string `xml:"code"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/xml" {
+			writer.Header().Set("Content-Type", "application/xml")
+			writer.WriteHeader(http.StatusBadRequest)
+			_, _ = writer.Write([]byte(`<error><code>bad</code></error>`))
+			return
+		}
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"code":"bad","extra":true}`))
+	}))
+	defer server.Close()
+
+	strict := NewGenericJSONErrorClient[int, testAPIError](NewJSON(nil, WithJSONConfig(JSONConfig{DisallowUnknownFields: true})))
+	_, err := strict.StreamPOST(context.Background(), server.URL, nil)
+	if apiErr := new(ResponseError[testAPIError]); errors.As(err, &apiErr) || !errors.Is(err, ErrStatusCode) {
+		t.Errorf("StreamPOST() error = %v, want the undecoded error", err)
+	}
+	_, err = NewGenericJSONErrorClient[int, testAPIError](nil).StreamPOST(context.Background(), server.URL, nil)
+	if apiErr := new(ResponseError[testAPIError]); !errors.As(err, &apiErr) || apiErr.Value.Code != "bad" {
+		t.Errorf("StreamPOST() error = %v", err)
+	}
+
+	client := NewGenericCodecClient[int](NewCodecClient(nil, []Codec{JSONCodec{}, XMLCodec{}}))
+	_, err = client.GET(context.Background(), server.URL+"/xml", nil)
+	if apiErr := new(ResponseError[xmlError]); !errors.As(DecodeError[xmlError](err), &apiErr) || apiErr.Value.Code != "bad" {
+		t.Errorf("DecodeError() error = %v", err)
+	}
+}
+
+func TestGenericJSONErrorClient_Clone(t *testing.T) {
+	client := NewGenericJSONErrorClient[int, testAPIError](NewJSON(nil))
+	clone := client.Clone()
+	if clone == client || clone.Client == client.Client {
+		t.Errorf("Clone() returned the same instance")
+	}
+}
@@ -1,8 +1,9 @@
 package chttp
 
 import (
+	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 // with automated marshaling of the request body and unmarshaling response body.
 type JSONClient struct {
 	*Client
+	// decodeError maps the errors returned by the JSONClient.UnmarshalHTTPResponse, see GenericJSONErrorClient.
+	decodeError func(err error) error
 }
 
 // JSON wraps Client with the JSONClient.
@@ -30,6 +33,20 @@ func NewJSON(client *http.Client, options ...Option) *JSONClient {
 
 // Request prepares the request by marshaling request body and tries to unmarshal response
 // with the JSONClient.UnmarshalHTTPResponse function.
+//
+// Fields of the body structure tagged with `path`, `query`, and `header` tags are lifted into the url and headers,
+// and only the remainder is marshaled as the request body:
+//
+//	type FindPetsRequest struct {
+//		Owner  string    `path:"owner"`
+//		Status []string  `query:"status,omitempty"`
+//		Since  time.Time `query:"since" layout:"2006-01-02"`
+//		ID     string    `header:"X-Request-Id"`
+//		Filter Filter    `json:"filter"`
+//	}
+//
+// Path parameters replace the `{name}` expressions in the url, e.g. `/owners/{owner}/pets`.
+// See the Template function for the supported expressions.
 func (c *JSONClient) Request(
 	ctx context.Context,
 	method string,
@@ -37,41 +54,177 @@ func (c *JSONClient) Request(
 	body interface{},
 	result interface{},
 ) (err error) {
-	data, err := marshal(body)
+	reader, params, err := c.prepare(body)
 	if err != nil {
 		return err
 	}
-	res, err := c.Client.Request(ctx, method, url, data)
+	res, err := c.Client.request(ctx, method, url, reader, params)
+	return c.UnmarshalHTTPResponse(res, err, &result)
+}
+
+// prepare lifts the request parameters from the body and marshals the remainder.
+func (c *JSONClient) prepare(body interface{}) (io.Reader, *requestParams, error) {
+	body, params, err := splitParams(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := marshal(c.jsonConfig, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bytes.NewBuffer(data), params, nil
+}
+
+// StreamRequest is the same as the JSONClient.Request, but the request body is streamed
+// with the json.Encoder through the io.Pipe without buffering it in memory.
+// The body will be encoded again on redirects and retries.
+func (c *JSONClient) StreamRequest(
+	ctx context.Context,
+	method string,
+	url string,
+	body interface{},
+	result interface{},
+) (err error) {
+	body, params, err := splitParams(body)
+	if err != nil {
+		return err
+	}
+	var stream io.Reader
+	if body != nil {
+		getBody := func() (io.ReadCloser, error) {
+			return newPipeReader(func(writer io.Writer) error {
+				return c.jsonConfig.encode(writer, body)
+			}), nil
+		}
+		reader, _ := getBody()
+		stream = NewBody(reader, -1, getBody)
+	}
+	res, err := c.Client.request(ctx, method, url, stream, params)
 	return c.UnmarshalHTTPResponse(res, err, &result)
 }
 
 // UnmarshalHTTPResponse tries to unmarshal the response body into the given result interface.
 // Result should be reference type and not nil.
 func (c *JSONClient) UnmarshalHTTPResponse(response *http.Response, httpErr error, result interface{}) (err error) {
+	err = c.Client.unmarshalHTTPResponse(response, httpErr, result, nil)
+	if c.decodeError != nil {
+		return c.decodeError(err)
+	}
+	return err
+}
+
+// unmarshalHTTPResponse decodes the response body with the json.Decoder without buffering it in memory,
+// the decoding is configured with the WithJSONConfig option. The body size is limited with the WithMaxResponseSize
+// option, the body of the unsuccessful response is read into the Error.Body up to the same limit.
+func (c *Client) unmarshalHTTPResponse(
+	response *http.Response,
+	httpErr error,
+	result interface{},
+	success func(statusCode int) bool,
+) (err error) {
+	return c.decodeHTTPResponse(response, httpErr, result, success, c.jsonCodecs())
+}
+
+// decodeHTTPResponse decodes the response body with the codec selected by the response Content-Type
+// according to the StatusPolicy. The success function overrides the StatusPolicy.Success if it's set.
+// The empty body leaves the result untouched.
+func (c *Client) decodeHTTPResponse(
+	response *http.Response,
+	httpErr error,
+	result interface{},
+	success func(statusCode int) bool,
+	codecs []Codec,
+) (err error) {
 	if httpErr != nil {
-		return newError(response, nil, fmt.Errorf("requesting error: %w", err))
+		return NewError(response, nil, fmt.Errorf("requesting error: %w", httpErr))
 	}
 	defer func() {
 		if response != nil && response.Body != nil {
 			_ = response.Body.Close()
 		}
 	}()
-	data, err := io.ReadAll(response.Body)
-	if err != nil {
-		return newError(response, data, fmt.Errorf("reading response body error: %w", err))
+	policy := c.statusPolicy(response.Request)
+	if success == nil {
+		success = policy.success
+	}
+	body := limitBody(response.Body, c.maxResponseSize)
+	if target, ok := policy.Targets[response.StatusCode]; ok {
+		if err = decodeBody(response, body, target, codecs); err != nil {
+			return err
+		}
+		return &TargetError{StatusCode: response.StatusCode, Target: target}
+	}
+	if containsStatus(policy.Empty, response.StatusCode) {
+		resetResult(result)
+		return decodeBody(response, body, nil, codecs)
 	}
-	if response.StatusCode >= http.StatusMultipleChoices {
-		return newError(response, data, nil)
+	if !success(response.StatusCode) {
+		return c.statusError(response, codecs)
 	}
-	if len(data) > 0 && result != nil {
-		err = json.Unmarshal(data, &result)
+	if policy.Check != nil {
+		data, err := io.ReadAll(body)
 		if err != nil {
-			return newError(response, data, fmt.Errorf("unmarshaling response error: %w", err))
+			return NewError(response, errorBody(data), fmt.Errorf("reading response body error: %w", err))
 		}
+		if err = policy.Check(response, data); err != nil {
+			return NewError(response, errorBody(data), fmt.Errorf("response check error: %w", err))
+		}
+		body = bytes.NewReader(data)
+	}
+	return decodeBody(response, body, result, codecs)
+}
+
+// decodeBody decodes the body into the result, or discards it if the result is nil.
+// The Error of the failed decoding keeps the prefix of the body read so far.
+func decodeBody(response *http.Response, body io.Reader, result interface{}, codecs []Codec) (err error) {
+	prefix := &prefixReader{reader: body, limit: maxErrorBodySize}
+	if result == nil {
+		if _, err = io.Copy(io.Discard, prefix); err != nil {
+			return NewError(response, prefix.data, fmt.Errorf("reading response body error: %w", err))
+		}
+		return nil
+	}
+	err = selectCodec(codecs, response).Decode(prefix, unwrapResult(&result))
+	if err == io.EOF {
+		return nil
+	}
+	if errors.Is(err, ErrResponseTooLarge) {
+		return NewError(response, prefix.data, fmt.Errorf("reading response body error: %w", err))
+	}
+	if err != nil {
+		return NewError(response, prefix.data, fmt.Errorf("unmarshaling response error: %w", err))
 	}
 	return nil
 }
 
+// statusError reads the body of the unsuccessful response into the Error. The problem details are decoded
+// with the client JSONConfig and problem types, and the Error.UnmarshalTo uses the codec selected by the response
+// Content-Type. The body is not closed.
+func (c *Client) statusError(response *http.Response, codecs []Codec) *Error {
+	data, err := c.readErrorBody(response)
+	if err != nil {
+		return NewError(response, data, fmt.Errorf("reading response body error: %w", err))
+	}
+	return newClientError(c, selectCodec(codecs, response), response, data, nil)
+}
+
+// maxErrorBodySize is the maximal size of the response body kept in the Error.
+const maxErrorBodySize = 64 << 10
+
+// readErrorBody reads the body of the unsuccessful response up to the maxErrorBodySize
+// or the smaller WithMaxResponseSize limit.
+func (c *Client) readErrorBody(response *http.Response) ([]byte, error) {
+	limit := int64(maxErrorBodySize)
+	if c.maxResponseSize > 0 && c.maxResponseSize < limit {
+		limit = c.maxResponseSize
+	}
+	return io.ReadAll(io.LimitReader(response.Body, limit))
+}
+
+func isSuccess(statusCode int) bool {
+	return statusCode < http.StatusMultipleChoices
+}
+
 // Method returns a function implementation of the HTTP Method from the Client by its name.
 // Returns Client.GET as the default method.
 func (c *JSONClient) Method(
@@ -126,6 +279,21 @@ func (c *JSONClient) PATCH(ctx context.Context, url string, body interface{}, re
 	return c.Request(ctx, http.MethodPatch, url, body, &result)
 }
 
+// StreamPOST is an alias to do the StreamRequest with the http.MethodPost method.
+func (c *JSONClient) StreamPOST(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.StreamRequest(ctx, http.MethodPost, url, body, &result)
+}
+
+// StreamPUT is an alias to do the StreamRequest with the http.MethodPut method.
+func (c *JSONClient) StreamPUT(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.StreamRequest(ctx, http.MethodPut, url, body, &result)
+}
+
+// StreamPATCH is an alias to do the StreamRequest with the http.MethodPatch method.
+func (c *JSONClient) StreamPATCH(ctx context.Context, url string, body interface{}, result interface{}) error {
+	return c.StreamRequest(ctx, http.MethodPatch, url, body, &result)
+}
+
 // DELETE is an alias to do the Request with the http.MethodDelete method.
 func (c *JSONClient) DELETE(ctx context.Context, url string, body interface{}, result interface{}) error {
 	return c.Request(ctx, http.MethodDelete, url, body, &result)
@@ -151,11 +319,11 @@ func (c *JSONClient) Clone() *JSONClient {
 	return JSON(c.Client.Clone())
 }
 
-func marshal(body interface{}) (data []byte, err error) {
+func marshal(config *JSONConfig, body interface{}) (data []byte, err error) {
 	if body != nil {
-		data, err = json.Marshal(body)
+		data, err = config.marshal(body)
 		if err != nil {
-			return nil, newError(nil, nil, fmt.Errorf("marshaling request error: %w", err))
+			return nil, NewError(nil, nil, fmt.Errorf("marshaling request error: %w", err))
 		}
 	}
 	return data, nil
@@ -0,0 +1,79 @@
+package chttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONConfig is the configuration of the JSON encoding and decoding of the JSONClient, GenericJSONClient,
+// StreamJSON, RequestBuilder, and the default JSONCodec of the CodecClient. See the WithJSONConfig option.
+type JSONConfig struct {
+	// DisallowUnknownFields makes the decoding fail if the object has the keys, which do not match
+	// any exported field of the destination structure.
+	DisallowUnknownFields bool
+	// UseNumber makes the decoder unmarshal numbers into the interface{} as the json.Number instead of the float64.
+	UseNumber bool
+	// DisableHTMLEscape disables escaping of the `<`, `>`, and `&` characters in the JSON strings.
+	DisableHTMLEscape bool
+	// Prefix and Indent enable the indentation of the encoded JSON, see json.MarshalIndent.
+	Prefix string
+	Indent string
+	// Marshal replaces the json.Marshal function, encoder settings are ignored if it's set.
+	Marshal func(value interface{}) ([]byte, error)
+	// Unmarshal replaces the json.Unmarshal function, decoder settings are ignored if it's set.
+	// The response body is read into the memory before unmarshaling.
+	Unmarshal func(data []byte, value interface{}) error
+}
+
+// marshal encodes the value without the trailing newline, as the json.Marshal does.
+func (c *JSONConfig) marshal(value interface{}) ([]byte, error) {
+	if c == nil {
+		return json.Marshal(value)
+	}
+	if c.Marshal != nil {
+		return c.Marshal(value)
+	}
+	buffer := new(bytes.Buffer)
+	if err := c.encode(buffer, value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// encode writes the encoded value followed by the newline, as the json.Encoder does.
+func (c *JSONConfig) encode(writer io.Writer, value interface{}) error {
+	if c != nil && c.Marshal != nil {
+		data, err := c.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(append(data, '\n'))
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	if c != nil {
+		encoder.SetEscapeHTML(!c.DisableHTMLEscape)
+		encoder.SetIndent(c.Prefix, c.Indent)
+	}
+	return encoder.Encode(value)
+}
+
+// unmarshal decodes the data as the json.Unmarshal does, with the decoder settings.
+func (c *JSONConfig) unmarshal(data []byte, value interface{}) error {
+	return unmarshal(JSONCodec{Config: c}, data, value)
+}
+
+// newDecoder creates the json.Decoder with the decoder settings.
+func (c *JSONConfig) newDecoder(reader io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(reader)
+	if c != nil {
+		if c.DisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+		if c.UseNumber {
+			decoder.UseNumber()
+		}
+	}
+	return decoder
+}
@@ -0,0 +1,159 @@
+package chttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithJSONConfig_encode(t *testing.T) {
+	type request struct {
+		Query string `json:"query"`
+	}
+	tests := []struct {
+		name   string
+		config JSONConfig
+		want   string
+	}{
+		{name: "default", config: JSONConfig{}, want: `{"query":"a\u003cb"}`},
+		{name: "no escape", config: JSONConfig{DisableHTMLEscape: true}, want: `{"query":"a<b"}`},
+		{name: "indent", config: JSONConfig{Indent: "  "}, want: "{\n  \"query\": \"a\\u003cb\"\n}"},
+		{
+			name: "custom",
+			config: JSONConfig{Marshal: func(value interface{}) ([]byte, error) {
+				return []byte(`"custom"`), nil
+			}},
+			want: `"custom"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				data, _ := io.ReadAll(request.Body)
+				got = append(got, strings.TrimSuffix(string(data), "\n"))
+			}))
+			defer server.Close()
+
+			client := NewJSON(nil, WithJSONConfig(tt.config))
+			body := request{Query: "a<b"}
+			if err := client.POST(context.Background(), server.URL, body, nil); err != nil {
+				t.Fatalf("POST() error = %v", err)
+			}
+			if err := client.StreamRequest(context.Background(), http.MethodPost, server.URL, body, nil); err != nil {
+				t.Fatalf("StreamRequest() error = %v", err)
+			}
+			if err := client.Clone().NewRequest(http.MethodPost, server.URL).JSON(body).Into(context.Background(), nil); err != nil {
+				t.Fatalf("Into() error = %v", err)
+			}
+			if want := []string{tt.want, tt.want, tt.want}; !reflect.DeepEqual(got, want) {
+				t.Errorf("POST() got = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithJSONConfig_decode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id": 9007199254740993, "extra": true}`))
+	}))
+	defer server.Close()
+	type pet struct {
+		ID json.Number `json:"id"`
+	}
+
+	var strict pet
+	err := NewJSON(nil, WithJSONConfig(JSONConfig{DisallowUnknownFields: true})).GET(context.Background(), server.URL, nil, &strict)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Errorf("GET() wrong error = %v", err)
+	}
+
+	var number map[string]interface{}
+	err = NewJSON(nil, WithJSONConfig(JSONConfig{UseNumber: true})).GET(context.Background(), server.URL, nil, &number)
+	if err != nil || number["id"] != json.Number("9007199254740993") {
+		t.Errorf("GET() got = %v, error = %v", number, err)
+	}
+
+	var calls int
+	client := NewGenericJSONClient[pet](NewJSON(nil, WithJSONConfig(JSONConfig{
+		Unmarshal: func(data []byte, value interface{}) error {
+			calls++
+			return json.Unmarshal(data, value)
+		},
+	})))
+	got, err := client.GET(context.Background(), server.URL, nil)
+	if err != nil || got.ID != "9007199254740993" || calls != 1 {
+		t.Errorf("GET() got = %v, calls = %d, error = %v", got, calls, err)
+	}
+	stream := client.Stream(context.Background(), http.MethodGet, server.URL, nil)
+	for stream.Next() {
+		if stream.Value().ID != "9007199254740993" {
+			t.Errorf("Stream() got = %v", stream.Value())
+		}
+	}
+	if stream.Err() != nil || calls != 2 {
+		t.Errorf("Stream() calls = %d, error = %v", calls, stream.Err())
+	}
+}
+
+func TestWithJSONConfig_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/problem" {
+			writer.Header().Set("Content-Type", "application/problem+json")
+		}
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"title": "Bad", "id": 9007199254740993}`))
+	}))
+	defer server.Close()
+
+	var calls int
+	client := NewJSON(nil, WithJSONConfig(JSONConfig{
+		Unmarshal: func(data []byte, value interface{}) error {
+			calls++
+			decoder := json.NewDecoder(strings.NewReader(string(data)))
+			decoder.UseNumber()
+			return decoder.Decode(value)
+		},
+	}))
+
+	err := client.GET(context.Background(), server.URL+"/problem", nil, nil)
+	var problem *Problem
+	if !errors.As(err, &problem) || problem.Title != "Bad" || calls != 1 {
+		t.Fatalf("GET() problem = %v, calls = %d, error = %v", problem, calls, err)
+	}
+	var id json.Number
+	if ok, err := problem.Extension("id", &id); !ok || err != nil || id != "9007199254740993" {
+		t.Errorf("Extension() got = %v, error = %v", id, err)
+	}
+
+	err = client.GET(context.Background(), server.URL, nil, nil)
+	got, err := ErrorUnmarshalTo[map[string]interface{}](err)
+	if err != nil || got["id"] != json.Number("9007199254740993") || calls != 2 {
+		t.Errorf("ErrorUnmarshalTo() got = %v, calls = %d, error = %v", got, calls, err)
+	}
+}
+
+func TestWithJSONConfig_params(t *testing.T) {
+	type request struct {
+		ID    string `path:"id"`
+		Query string `json:"query"`
+	}
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, _ := io.ReadAll(request.Body)
+		got = request.URL.Path + " " + strings.TrimSuffix(string(data), "\n")
+	}))
+	defer server.Close()
+
+	client := NewJSON(nil, WithJSONConfig(JSONConfig{DisableHTMLEscape: true}))
+	err := client.POST(context.Background(), server.URL+"/pet/{id}", request{ID: "1", Query: "a<b"}, nil)
+	if want := `/pet/1 {"query":"a<b"}`; err != nil || got != want {
+		t.Errorf("POST() got = %q, want %q, error = %v", got, want, err)
+	}
+}
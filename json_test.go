@@ -3,11 +3,13 @@ package chttp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -175,7 +177,7 @@ func TestJSONClient_Request(t *testing.T) {
 		t.Errorf("Request() error wanted")
 		return
 	}
-	if err.Error() != "unmarshaling response error: unexpected end of JSON input" {
+	if err.Error() != "GET "+server.URL+": unmarshaling response error: unexpected EOF" {
 		t.Errorf("Request() wrong error: %q", err.Error())
 	}
 }
@@ -189,7 +191,7 @@ func TestJSONClient_Request_status(t *testing.T) {
 		t.Errorf("Request() error wanted")
 		return
 	}
-	if err.Error() != "wrong status code, status_code=500" {
+	if err.Error() != "GET "+server.URL+": wrong status code, status_code=500" {
 		t.Errorf("Request() wrong error: %q", err.Error())
 	}
 }
@@ -269,3 +271,90 @@ func testServerJSON(status int, response interface{}) *httptest.Server {
 		_ = json.NewEncoder(writer).Encode(response)
 	}))
 }
+
+func TestJSONClient_Request_maxResponseSize(t *testing.T) {
+	type example struct {
+		Data string `json:"data"`
+	}
+	large := example{Data: string(make([]byte, 1024))}
+	tests := []struct {
+		name     string
+		status   int
+		response interface{}
+		result   interface{}
+		tooLarge bool
+		wantBody int
+	}{
+		{name: "small", status: http.StatusOK, response: example{Data: "ok"}, result: new(example)},
+		{name: "large", status: http.StatusOK, response: large, result: new(example), tooLarge: true},
+		{name: "large without result", status: http.StatusOK, response: large, result: nil, tooLarge: true},
+		{name: "error", status: http.StatusBadRequest, response: large, result: new(example), wantBody: 100},
+		{name: "small error", status: http.StatusBadRequest, response: "bad", result: new(example), wantBody: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServerJSON(tt.status, tt.response)
+			defer server.Close()
+
+			err := NewJSON(nil, WithMaxResponseSize(100)).GET(context.TODO(), server.URL, nil, tt.result)
+			if errors.Is(err, ErrResponseTooLarge) != tt.tooLarge {
+				t.Errorf("GET() error = %v", err)
+			}
+			if tt.status == http.StatusOK {
+				return
+			}
+			cErr := new(Error)
+			if !errors.As(err, &cErr) || !cErr.IsStatusCode() {
+				t.Errorf("GET() error = %v", err)
+				return
+			}
+			if len(cErr.Body) != tt.wantBody {
+				t.Errorf("GET() wrong body size: %d, want %d", len(cErr.Body), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestJSONClient_Request_errorBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantBody string
+	}{
+		{name: "large error", status: http.StatusBadRequest, body: strings.Repeat("a", 100<<10), wantBody: strings.Repeat("a", 64<<10)},
+		{name: "invalid body", status: http.StatusOK, body: `{"data":`, wantBody: `{"data":`},
+		{name: "large invalid body", status: http.StatusOK, body: `"` + strings.Repeat("a", 100<<10), wantBody: `"` + strings.Repeat("a", 64<<10-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(tt.status)
+				_, _ = writer.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var result string
+			err := NewJSON(nil).GET(context.TODO(), server.URL, nil, &result)
+			cErr := new(Error)
+			if !errors.As(err, &cErr) {
+				t.Fatalf("GET() error = %v", err)
+			}
+			if string(cErr.Body) != tt.wantBody {
+				t.Errorf("GET() wrong body size: %d, want %d", len(cErr.Body), len(tt.wantBody))
+			}
+		})
+	}
+}
+
+func TestJSONClient_Request_trailingData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte(`{"foo":"bar"} {}`))
+	}))
+	defer server.Close()
+	var result map[string]string
+	if err := NewJSON(nil).GET(context.TODO(), server.URL, nil, &result); err == nil {
+		t.Errorf("GET() error wanted")
+	}
+}
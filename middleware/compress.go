@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/spyzhov/chttp"
+)
+
+// NoCompression is the CompressConfig Level to send the bodies in the content encoding format without
+// the compression, as the zero Level means flate.DefaultCompression.
+const NoCompression = flate.HuffmanOnly - 1
+
+// CompressConfig is a configuration of the CompressRequest middleware.
+type CompressConfig struct {
+	// Encoding is one of the `gzip` or `deflate` content encodings, `gzip` by default.
+	Encoding string
+	// Level is the compression level from the compress/flate package, flate.DefaultCompression by default.
+	// Use the NoCompression instead of the flate.NoCompression.
+	Level int
+	// MinSize is the minimal size of the request body to be compressed.
+	// Bodies with unknown length are always compressed.
+	MinSize int64
+	// ContentTypes is a list of the media types to be compressed, e.g. `application/json` or `text/*`.
+	// Empty list allows any content type.
+	ContentTypes []string
+}
+
+// CompressRequest is a chttp.Middleware constructor to compress the request bodies and set the `Content-Encoding`
+// header. If the server responds with the `415 Unsupported Media Type` status code, the request will be resent
+// uncompressed (if the body could be rewound with the http.Request GetBody), and all next requests to the same host
+// will not be compressed.
+func CompressRequest(config CompressConfig) chttp.Middleware {
+	if config.Encoding == "" {
+		config.Encoding = "gzip"
+	}
+	switch config.Level {
+	case 0:
+		config.Level = flate.DefaultCompression
+	case NoCompression:
+		config.Level = flate.NoCompression
+	}
+	_, configErr := config.writer(io.Discard)
+	var unsupported sync.Map
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if !config.allowed(request) {
+			return next(request)
+		}
+		if _, ok := unsupported.Load(request.URL.Host); ok {
+			return next(request)
+		}
+		if configErr != nil {
+			return nil, configErr
+		}
+
+		compressed := request.Clone(request.Context())
+		compressed.Body = config.compress(request.Body)
+		compressed.ContentLength = -1
+		compressed.Header.Set("Content-Encoding", config.Encoding)
+		compressed.Header.Del("Content-Length")
+		if request.GetBody != nil {
+			compressed.GetBody = func() (io.ReadCloser, error) {
+				body, err := request.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				return config.compress(body), nil
+			}
+		}
+
+		response, err := next(compressed)
+		if err != nil || response.StatusCode != http.StatusUnsupportedMediaType {
+			return response, err
+		}
+		unsupported.Store(request.URL.Host, true)
+		if request.GetBody == nil {
+			return response, nil
+		}
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		uncompressed := request.Clone(request.Context())
+		uncompressed.Body = body
+		return next(uncompressed)
+	}
+}
+
+func (c CompressConfig) allowed(request *http.Request) bool {
+	if request.Body == nil || request.Body == http.NoBody || request.URL == nil {
+		return false
+	}
+	if request.Header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if request.ContentLength > 0 && request.ContentLength < c.MinSize {
+		return false
+	}
+	if len(c.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.ContentTypes {
+		if allowed == mediaType ||
+			(strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CompressConfig) writer(writer io.Writer) (io.WriteCloser, error) {
+	switch c.Encoding {
+	case "gzip":
+		return gzip.NewWriterLevel(writer, c.Level)
+	case "deflate":
+		return zlib.NewWriterLevel(writer, c.Level)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %q", c.Encoding)
+	}
+}
+
+// compress streams the compressed body through the io.Pipe.
+func (c CompressConfig) compress(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &compressReader{config: c, body: body, pr: pr, pw: pw}
+}
+
+// compressReader starts the compression only on the first Read call, so the body isn't read if the request
+// is never sent, and no goroutine will be leaked if the body was closed or dropped before reading.
+type compressReader struct {
+	config CompressConfig
+	body   io.ReadCloser
+	once   sync.Once
+	pr     *io.PipeReader
+	pw     *io.PipeWriter
+}
+
+func (r *compressReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {
+		go r.write()
+	})
+	return r.pr.Read(p)
+}
+
+func (r *compressReader) Close() error {
+	r.once.Do(func() {
+		_ = r.body.Close()
+	})
+	return r.pr.Close()
+}
+
+func (r *compressReader) write() {
+	defer func() {
+		_ = r.body.Close()
+	}()
+	writer, err := r.config.writer(r.pw)
+	if err == nil {
+		_, err = io.Copy(writer, r.body)
+		if cErr := writer.Close(); err == nil {
+			err = cErr
+		}
+	}
+	_ = r.pw.CloseWithError(err)
+}
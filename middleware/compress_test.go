@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+func ExampleCompressRequest() {
+	client := chttp.NewJSON(nil)
+	client.With(CompressRequest(CompressConfig{
+		Encoding:     "gzip",
+		MinSize:      1024,
+		ContentTypes: []string{"application/json"},
+	}))
+}
+
+func testCompressServer(t *testing.T, unsupported bool, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(calls, 1)
+		var (
+			reader io.Reader = request.Body
+			err    error
+		)
+		switch request.Header.Get("Content-Encoding") {
+		case "gzip":
+			reader, err = gzip.NewReader(request.Body)
+		case "deflate":
+			reader, err = zlib.NewReader(request.Body)
+		}
+		if err != nil {
+			t.Errorf("reader error = %v", err)
+		}
+		if unsupported && request.Header.Get("Content-Encoding") != "" {
+			writer.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Errorf("ReadAll() error = %v", err)
+		}
+		writer.Header().Set("X-Content-Encoding", request.Header.Get("Content-Encoding"))
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(data)
+	}))
+}
+
+func TestCompressRequest(t *testing.T) {
+	var calls int32
+	server := testCompressServer(t, false, &calls)
+	defer server.Close()
+
+	large := strings.Repeat(`{"event":"click"}`, 1000)
+	tests := []struct {
+		name        string
+		config      CompressConfig
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:   "gzip",
+			config: CompressConfig{},
+			body:   large,
+			want:   "gzip",
+		},
+		{
+			name:   "deflate",
+			config: CompressConfig{Encoding: "deflate", Level: 9},
+			body:   large,
+			want:   "deflate",
+		},
+		{
+			name:   "small body",
+			config: CompressConfig{MinSize: 1024},
+			body:   "{}",
+			want:   "",
+		},
+		{
+			name:        "allowed content type",
+			config:      CompressConfig{ContentTypes: []string{"text/*", "application/json"}},
+			contentType: "application/json; charset=utf-8",
+			body:        large,
+			want:        "gzip",
+		},
+		{
+			name:        "not allowed content type",
+			config:      CompressConfig{ContentTypes: []string{"text/*", "application/json"}},
+			contentType: "image/png",
+			body:        large,
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := chttp.NewClient(nil)
+			client.With(CompressRequest(tt.config))
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			resp, err := client.Do(request)
+			if err != nil {
+				t.Errorf("Do() error = %v", err)
+				return
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			data, _ := io.ReadAll(resp.Body)
+			if string(data) != tt.body {
+				t.Errorf("wrong body: %d bytes", len(data))
+			}
+			if got := resp.Header.Get("X-Content-Encoding"); got != tt.want {
+				t.Errorf("wrong encoding: %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressRequest_level(t *testing.T) {
+	large := strings.Repeat(`{"event":"click"}`, 1000)
+	tests := []struct {
+		name       string
+		level      int
+		compressed bool
+	}{
+		{name: "default", level: 0, compressed: true},
+		{name: "best speed", level: 1, compressed: true},
+		{name: "no compression", level: NoCompression, compressed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var size int
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				data, _ := io.ReadAll(request.Body)
+				size = len(data)
+			}))
+			defer server.Close()
+
+			client := chttp.NewClient(nil)
+			client.With(CompressRequest(CompressConfig{Level: tt.level}))
+			resp, err := client.POST(context.Background(), server.URL, []byte(large))
+			if err != nil {
+				t.Fatalf("POST() error = %v", err)
+			}
+			_ = resp.Body.Close()
+			if compressed := size < len(large); compressed != tt.compressed {
+				t.Errorf("POST() sent %d bytes of %d", size, len(large))
+			}
+		})
+	}
+}
+
+func TestCompressRequest_unsupported(t *testing.T) {
+	var calls int32
+	server := testCompressServer(t, true, &calls)
+	defer server.Close()
+
+	client := chttp.NewClient(nil)
+	client.With(CompressRequest(CompressConfig{}))
+	for i, want := range []int32{2, 3} {
+		resp, err := client.POST(context.Background(), server.URL, []byte("data"))
+		if err != nil {
+			t.Errorf("POST() error = %v", err)
+			return
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(data) != "data" {
+			t.Errorf("#%d: wrong response: %d %q", i, resp.StatusCode, data)
+		}
+		if got := atomic.LoadInt32(&calls); got != want {
+			t.Errorf("#%d: wrong calls count: %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestCompressRequest_GetBody(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://example.com/", bytes.NewReader([]byte("data")))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader([]byte("data"))), nil
+	}
+	_, _ = CompressRequest(CompressConfig{})(request, func(request *http.Request) (*http.Response, error) {
+		for i := 0; i < 2; i++ {
+			body, err := request.GetBody()
+			if err != nil {
+				t.Errorf("GetBody() error = %v", err)
+				return nil, err
+			}
+			reader, err := gzip.NewReader(body)
+			if err != nil {
+				t.Errorf("gzip.NewReader() error = %v", err)
+				return nil, err
+			}
+			if data, _ := io.ReadAll(reader); string(data) != "data" {
+				t.Errorf("wrong body: %q", data)
+			}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+}
+
+type testCountingBody struct {
+	io.Reader
+	read   int32
+	closed int32
+}
+
+func (b *testCountingBody) Read(p []byte) (int, error) {
+	atomic.AddInt32(&b.read, 1)
+	return b.Reader.Read(p)
+}
+
+func (b *testCountingBody) Close() error {
+	atomic.AddInt32(&b.closed, 1)
+	return nil
+}
+
+func TestCompressRequest_notSent(t *testing.T) {
+	body := &testCountingBody{Reader: strings.NewReader("data")}
+	request := httptest.NewRequest(http.MethodPost, "http://example.com/", body)
+	request.GetBody = func() (io.ReadCloser, error) {
+		return body, nil
+	}
+	_, _ = CompressRequest(CompressConfig{})(request, func(request *http.Request) (*http.Response, error) {
+		if _, err := request.GetBody(); err != nil {
+			t.Errorf("GetBody() error = %v", err)
+		}
+		_ = request.Body.Close()
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	if read := atomic.LoadInt32(&body.read); read != 0 {
+		t.Errorf("body is read %d times", read)
+	}
+	if closed := atomic.LoadInt32(&body.closed); closed != 1 {
+		t.Errorf("body is closed %d times", closed)
+	}
+}
+
+func TestCompressRequest_encoding(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "http://example.com/", bytes.NewReader([]byte("data")))
+	_, err := CompressRequest(CompressConfig{Encoding: "br"})(request, func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	if err == nil {
+		t.Errorf("error wanted")
+	}
+}
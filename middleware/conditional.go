@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/spyzhov/chttp"
+)
+
+// Predicate reports whether the request should go through the conditional middlewares.
+type Predicate func(request *http.Request) bool
+
+// When is a chttp.Middleware constructor to apply the middlewares only to the requests matched by the predicate,
+// other requests are passed as is.
+func When(predicate Predicate, middleware ...chttp.Middleware) chttp.Middleware {
+	chain := Chain(middleware...)
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if !predicate(request) {
+			return next(request)
+		}
+		return chain(request, next)
+	}
+}
+
+// ForHost applies the middlewares to the requests with the host matched by the pattern (path.Match syntax),
+// e.g. `*.example.com`. The pattern is matched against the host with and without the port.
+func ForHost(pattern string, middleware ...chttp.Middleware) chttp.Middleware {
+	return When(func(request *http.Request) bool {
+		return matchHost(pattern, request)
+	}, middleware...)
+}
+
+// ForPath applies the middlewares to the requests with the path matched by the pattern (path.Match syntax),
+// e.g. `/pet/*`. The `{name}` expressions of the path template match a single path segment, e.g. `/pet/{id}`.
+func ForPath(pattern string, middleware ...chttp.Middleware) chttp.Middleware {
+	pattern = templatePattern(pattern)
+	return When(func(request *http.Request) bool {
+		return matchPath(pattern, request)
+	}, middleware...)
+}
+
+// ForMethod applies the middlewares to the requests with one of the given HTTP methods.
+func ForMethod(methods []string, middleware ...chttp.Middleware) chttp.Middleware {
+	return When(func(request *http.Request) bool {
+		return matchMethod(methods, request)
+	}, middleware...)
+}
+
+// Chain combines the middlewares into the single one, the first middleware is called first.
+func Chain(middleware ...chttp.Middleware) chttp.Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		return chain(middleware, 0, next)(request)
+	}
+}
+
+// chain returns the handler of the middleware on the given position, so any middleware could call it several times.
+func chain(
+	middlewares []chttp.Middleware,
+	index int,
+	next func(request *http.Request) (*http.Response, error),
+) func(request *http.Request) (*http.Response, error) {
+	if index >= len(middlewares) {
+		return next
+	}
+	return func(request *http.Request) (*http.Response, error) {
+		return middlewares[index](request, chain(middlewares, index+1, next))
+	}
+}
+
+// Router dispatches the requests to the different middleware chains by the host and the path template.
+// Routes are checked in the order they were added, the first matched route is used. Requests without
+// the matched route go through the default chain.
+//
+//	router := middleware.NewRouter().
+//		Handle("api.github.com", "", githubAuth).
+//		Handle("*.example.com", "/v2/pets/{id}", exampleAuth, cache).
+//		Default(middleware.Trace(nil))
+//	client.With(router.Middleware())
+type Router struct {
+	mu       sync.RWMutex
+	routes   []route
+	fallback chttp.Middleware
+}
+
+type route struct {
+	host       string
+	path       string
+	middleware chttp.Middleware
+}
+
+// NewRouter creates an empty Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Handle adds the route for the requests with the host (path.Match syntax) and the path template, see ForHost
+// and ForPath for the details. Empty host or path matches any value.
+func (r *Router) Handle(host string, path string, middleware ...chttp.Middleware) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route{
+		host:       host,
+		path:       templatePattern(path),
+		middleware: Chain(middleware...),
+	})
+	return r
+}
+
+// Default sets the middlewares for the requests without the matched route.
+func (r *Router) Default(middleware ...chttp.Middleware) *Router {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = Chain(middleware...)
+	return r
+}
+
+// Middleware returns the chttp.Middleware that dispatches the requests.
+func (r *Router) Middleware() chttp.Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if middleware := r.match(request); middleware != nil {
+			return middleware(request, next)
+		}
+		return next(request)
+	}
+}
+
+func (r *Router) match(request *http.Request) chttp.Middleware {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, route := range r.routes {
+		if matchHost(route.host, request) && matchPath(route.path, request) {
+			return route.middleware
+		}
+	}
+	return r.fallback
+}
+
+var templateExpression = regexp.MustCompile(`\{[^{}/]*}`)
+
+// templatePattern replaces the `{name}` expressions of the path template with the path.Match wildcards.
+func templatePattern(pattern string) string {
+	return templateExpression.ReplaceAllString(pattern, "*")
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/spyzhov/chttp"
+)
+
+func ExampleNewRouter() {
+	router := NewRouter().
+		Handle("api.github.com", "", Headers(map[string]string{"Authorization": "token github"}, true)).
+		Handle("*.example.com", "/v2/pets/{id}", Headers(map[string]string{"Authorization": "Bearer example"}, true)).
+		Default(Trace(nil))
+	client := chttp.NewClient(nil)
+	client.With(router.Middleware())
+}
+
+func testTrace(name string) chttp.Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		request.Header.Add("X-Trace", name)
+		return next(request)
+	}
+}
+
+func TestConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Trace", strings.Join(request.Header.Values("X-Trace"), ","))
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	router := NewRouter().
+		Handle("127.0.0.1", "/pets/{id}", testTrace("pet"), testTrace("auth")).
+		Handle("*.example.com", "", testTrace("example")).
+		Handle("", "/store/*", testTrace("store")).
+		Default(testTrace("default"))
+
+	tests := []struct {
+		name       string
+		middleware chttp.Middleware
+		method     string
+		path       string
+		want       string
+	}{
+		{
+			name:       "when matched",
+			middleware: When(func(r *http.Request) bool { return r.URL.Query().Get("debug") != "" }, testTrace("a")),
+			path:       "/?debug=1",
+			want:       "a",
+		},
+		{
+			name:       "when not matched",
+			middleware: When(func(r *http.Request) bool { return r.URL.Query().Get("debug") != "" }, testTrace("a")),
+			path:       "/",
+			want:       "",
+		},
+		{
+			name:       "host",
+			middleware: ForHost("127.0.0.*", testTrace("a"), testTrace("b")),
+			path:       "/",
+			want:       "a,b",
+		},
+		{
+			name:       "host with port",
+			middleware: ForHost("127.0.0.1:*", testTrace("a")),
+			path:       "/",
+			want:       "a",
+		},
+		{
+			name:       "other host",
+			middleware: ForHost("*.example.com", testTrace("a")),
+			path:       "/",
+			want:       "",
+		},
+		{
+			name:       "path template",
+			middleware: ForPath("/pets/{id}/photos", testTrace("a")),
+			path:       "/pets/42/photos",
+			want:       "a",
+		},
+		{
+			name:       "path template segment",
+			middleware: ForPath("/pets/{id}", testTrace("a")),
+			path:       "/pets/42/photos",
+			want:       "",
+		},
+		{
+			name:       "method",
+			middleware: ForMethod([]string{http.MethodPost, http.MethodPut}, testTrace("a")),
+			method:     http.MethodPut,
+			path:       "/",
+			want:       "a",
+		},
+		{
+			name:       "other method",
+			middleware: ForMethod([]string{http.MethodPost, http.MethodPut}, testTrace("a")),
+			path:       "/",
+			want:       "",
+		},
+		{
+			name:       "chain",
+			middleware: Chain(testTrace("a"), ForMethod([]string{http.MethodGet}, testTrace("b")), testTrace("c")),
+			path:       "/",
+			want:       "a,b,c",
+		},
+		{
+			name:       "empty chain",
+			middleware: Chain(),
+			path:       "/",
+			want:       "",
+		},
+		{
+			name:       "router",
+			middleware: router.Middleware(),
+			path:       "/pets/1",
+			want:       "pet,auth",
+		},
+		{
+			name:       "router any host",
+			middleware: router.Middleware(),
+			path:       "/store/order",
+			want:       "store",
+		},
+		{
+			name:       "router default",
+			middleware: router.Middleware(),
+			path:       "/pets/1/photos",
+			want:       "default",
+		},
+		{
+			name:       "router without default",
+			middleware: NewRouter().Handle("", "/store", testTrace("store")).Middleware(),
+			path:       "/pets",
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			client := chttp.NewClient(nil)
+			client.With(tt.middleware)
+			response, err := client.Request(context.Background(), method, server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Request() error = %v", err)
+			}
+			_ = response.Body.Close()
+			if got := response.Header.Get("X-Trace"); got != tt.want {
+				t.Errorf("middlewares called = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
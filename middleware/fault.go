@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+// FaultRule describes which requests are affected by the Fault middleware and which fault should be injected.
+// All non-empty matchers should match the request to apply the rule.
+type FaultRule struct {
+	// Host is a pattern (path.Match syntax) for the request host, e.g. `*.example.com`. Empty matches any host.
+	Host string
+	// Path is a pattern (path.Match syntax) for the request path, e.g. `/pet/*`. Empty matches any path.
+	Path string
+	// Methods is a list of the HTTP methods to match. Empty matches any method.
+	Methods []string
+	// Headers is a list of the request headers with the expected values. Empty value matches any present header.
+	Headers map[string]string
+	// Percentage of the matched requests to be affected, in the range (0, 100].
+	Percentage float64
+
+	// Latency is a delay added before the request.
+	Latency time.Duration
+	// Err is a transport error returned instead of sending the request.
+	Err error
+	// StatusCode is a status code of the response returned instead of sending the request.
+	StatusCode int
+	// Header is a list of headers of the injected response.
+	Header http.Header
+	// Body is a body of the injected response.
+	Body []byte
+	// Truncate cuts the response body after TruncateAfter bytes with the io.ErrUnexpectedEOF error.
+	Truncate      bool
+	TruncateAfter int64
+	// Reset simulates the connection reset by peer. With the Truncate flag, the connection is reset while reading
+	// the response body.
+	Reset bool
+}
+
+// Fault is a chaos middleware that injects faults into the requests by the list of rules.
+// Fault is enabled by default, and could be toggled at runtime.
+type Fault struct {
+	mu      sync.Mutex
+	rules   []FaultRule
+	random  *rand.Rand
+	enabled bool
+}
+
+// NewFault creates a Fault with the given rules, seed is used to make the injection deterministic.
+func NewFault(seed int64, rules ...FaultRule) *Fault {
+	return &Fault{
+		rules:   rules,
+		random:  rand.New(rand.NewSource(seed)),
+		enabled: true,
+	}
+}
+
+// Enable activates the fault injection.
+func (f *Fault) Enable() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enabled = true
+}
+
+// Disable deactivates the fault injection, all requests will be passed as is.
+func (f *Fault) Disable() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enabled = false
+}
+
+// Enabled returns true if the fault injection is active.
+func (f *Fault) Enabled() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.enabled
+}
+
+// SetRules replaces the list of rules.
+func (f *Fault) SetRules(rules ...FaultRule) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = rules
+}
+
+// Seed resets the random generator with the given seed.
+func (f *Fault) Seed(seed int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.random.Seed(seed)
+}
+
+// Middleware returns the chttp.Middleware that injects faults.
+func (f *Fault) Middleware() chttp.Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		rule, ok := f.match(request)
+		if !ok {
+			return next(request)
+		}
+		return rule.apply(request, next)
+	}
+}
+
+func (f *Fault) match(request *http.Request) (FaultRule, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.enabled {
+		return FaultRule{}, false
+	}
+	for _, rule := range f.rules {
+		if rule.matches(request) && f.random.Float64()*100 < rule.Percentage {
+			return rule, true
+		}
+	}
+	return FaultRule{}, false
+}
+
+func (r FaultRule) matches(request *http.Request) bool {
+	if !matchHost(r.Host, request) || !matchPath(r.Path, request) || !matchMethod(r.Methods, request) {
+		return false
+	}
+	for name, value := range r.Headers {
+		values, ok := request.Header[http.CanonicalHeaderKey(name)]
+		if !ok || (value != "" && !contains(values, value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r FaultRule) apply(
+	request *http.Request,
+	next func(request *http.Request) (*http.Response, error),
+) (*http.Response, error) {
+	if r.Latency > 0 {
+		timer := time.NewTimer(r.Latency)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			closeRequestBody(request)
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if r.Err != nil {
+		closeRequestBody(request)
+		return nil, r.Err
+	}
+	if r.Reset && !r.Truncate {
+		closeRequestBody(request)
+		return nil, errConnectionReset()
+	}
+
+	var (
+		response *http.Response
+		err      error
+	)
+	if r.StatusCode != 0 {
+		closeRequestBody(request)
+		response = r.response(request)
+	} else {
+		response, err = next(request)
+		if err != nil {
+			return response, err
+		}
+	}
+	if r.Truncate && response.Body != nil {
+		failure := io.ErrUnexpectedEOF
+		if r.Reset {
+			failure = errConnectionReset()
+		}
+		response.Body = &truncatedBody{
+			ReadCloser: response.Body,
+			left:       r.TruncateAfter,
+			err:        failure,
+		}
+	}
+	return response, nil
+}
+
+func (r FaultRule) response(request *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
+
+type truncatedBody struct {
+	io.ReadCloser
+	left int64
+	err  error
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.left -= int64(n)
+	if err == io.EOF {
+		return n, err
+	}
+	if err == nil && b.left <= 0 {
+		err = b.err
+	}
+	return n, err
+}
+
+// closeRequestBody closes the body of the request, which is not sent, as the transport would do.
+func closeRequestBody(request *http.Request) {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+}
+
+func errConnectionReset() error {
+	return &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+}
+
+func matchHost(pattern string, request *http.Request) bool {
+	if pattern == "" {
+		return true
+	}
+	host := request.Host
+	if host == "" && request.URL != nil {
+		host = request.URL.Host
+	}
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+	return match(pattern, host) || match(pattern, hostname)
+}
+
+func matchPath(pattern string, request *http.Request) bool {
+	if pattern == "" {
+		return true
+	}
+	if request.URL == nil {
+		return false
+	}
+	return match(pattern, request.URL.Path)
+}
+
+func matchMethod(methods []string, request *http.Request) bool {
+	return len(methods) == 0 || contains(methods, request.Method)
+}
+
+func match(pattern, value string) bool {
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+func ExampleNewFault() {
+	fault := NewFault(42, FaultRule{
+		Path:       "/pet/*",
+		Methods:    []string{http.MethodGet},
+		Percentage: 10,
+		StatusCode: http.StatusServiceUnavailable,
+	})
+	client := chttp.NewClient(nil)
+	client.With(fault.Middleware())
+}
+
+func TestFault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+	testErr := errors.New("test error")
+
+	tests := []struct {
+		name     string
+		rule     FaultRule
+		path     string
+		header   string
+		wantErr  error
+		wantCode int
+		wantBody string
+		readErr  error
+	}{
+		{
+			name:     "not matched path",
+			rule:     FaultRule{Path: "/pet/*", Percentage: 100, Err: testErr},
+			path:     "/store/1",
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+		},
+		{
+			name:    "matched path",
+			rule:    FaultRule{Path: "/pet/*", Percentage: 100, Err: testErr},
+			path:    "/pet/1",
+			wantErr: testErr,
+		},
+		{
+			name:     "not matched header",
+			rule:     FaultRule{Headers: map[string]string{"X-Chaos": "on"}, Percentage: 100, Err: testErr},
+			header:   "off",
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+		},
+		{
+			name:    "matched header",
+			rule:    FaultRule{Headers: map[string]string{"X-Chaos": "on"}, Percentage: 100, Err: testErr},
+			header:  "on",
+			wantErr: testErr,
+		},
+		{
+			name:     "not matched method",
+			rule:     FaultRule{Methods: []string{http.MethodPost}, Percentage: 100, Err: testErr},
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+		},
+		{
+			name:     "zero percentage",
+			rule:     FaultRule{Percentage: 0, Err: testErr},
+			wantCode: http.StatusOK,
+			wantBody: "0123456789",
+		},
+		{
+			name:     "status code",
+			rule:     FaultRule{Host: "127.0.0.1", Percentage: 100, StatusCode: http.StatusBadGateway, Body: []byte("bad")},
+			wantCode: http.StatusBadGateway,
+			wantBody: "bad",
+		},
+		{
+			name:    "reset",
+			rule:    FaultRule{Percentage: 100, Reset: true},
+			wantErr: syscall.ECONNRESET,
+		},
+		{
+			name:     "truncate",
+			rule:     FaultRule{Percentage: 100, Truncate: true, TruncateAfter: 4},
+			wantCode: http.StatusOK,
+			wantBody: "0123",
+			readErr:  io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "truncate with reset",
+			rule:     FaultRule{Percentage: 100, Truncate: true, TruncateAfter: 2, Reset: true},
+			wantCode: http.StatusOK,
+			wantBody: "01",
+			readErr:  syscall.ECONNRESET,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := chttp.NewClient(nil)
+			client.With(NewFault(1, tt.rule).Middleware())
+			request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+tt.path, nil)
+			if tt.header != "" {
+				request.Header.Set("X-Chaos", tt.header)
+			}
+			resp, err := client.Do(request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("wrong response code: %d", resp.StatusCode)
+			}
+			data, err := io.ReadAll(resp.Body)
+			if !errors.Is(err, tt.readErr) {
+				t.Errorf("ReadAll() error = %v, want %v", err, tt.readErr)
+			}
+			if string(data) != tt.wantBody {
+				t.Errorf("wrong body: %q", data)
+			}
+		})
+	}
+}
+
+func TestFault_Toggle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	fault := NewFault(1, FaultRule{Percentage: 100, StatusCode: http.StatusTeapot})
+	client := chttp.NewClient(nil)
+	client.With(fault.Middleware())
+
+	for _, want := range []int{http.StatusTeapot, http.StatusOK, http.StatusTeapot} {
+		resp, err := client.GET(context.Background(), server.URL)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("wrong response code: %d, want %d", resp.StatusCode, want)
+		}
+		if fault.Enabled() {
+			fault.Disable()
+		} else {
+			fault.Enable()
+		}
+	}
+}
+
+func TestFault_Deterministic(t *testing.T) {
+	// want is the sequence of the injected (1) and passed (0) requests for the seed 7.
+	const want = "01100111101110110001"
+	fault := NewFault(7, FaultRule{Percentage: 50, StatusCode: http.StatusTeapot})
+	middleware := fault.Middleware()
+	run := func() string {
+		var result strings.Builder
+		for i := 0; i < len(want); i++ {
+			request := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			resp, _ := middleware(request, func(request *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+			})
+			if resp.StatusCode == http.StatusTeapot {
+				result.WriteByte('1')
+			} else {
+				result.WriteByte('0')
+			}
+		}
+		return result.String()
+	}
+	if got := run(); got != want {
+		t.Errorf("wrong injected sequence: %s, want %s", got, want)
+	}
+	fault.Seed(7)
+	if got := run(); got != want {
+		t.Errorf("wrong injected sequence after Seed(): %s, want %s", got, want)
+	}
+}
+
+type testClosedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *testClosedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestFault_closeRequestBody(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name string
+		rule FaultRule
+		ctx  context.Context
+	}{
+		{name: "error", rule: FaultRule{Percentage: 100, Err: errors.New("injected")}},
+		{name: "reset", rule: FaultRule{Percentage: 100, Reset: true}},
+		{name: "status code", rule: FaultRule{Percentage: 100, StatusCode: http.StatusTeapot}},
+		{name: "latency", rule: FaultRule{Percentage: 100, Latency: time.Hour}, ctx: canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &testClosedBody{Reader: strings.NewReader("data")}
+			request := httptest.NewRequest(http.MethodPost, "http://example.com/", body)
+			if tt.ctx != nil {
+				request = request.WithContext(tt.ctx)
+			}
+			resp, _ := NewFault(1, tt.rule).Middleware()(request, func(request *http.Request) (*http.Response, error) {
+				t.Errorf("request is sent")
+				return nil, errors.New("sent")
+			})
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+			if !body.closed {
+				t.Errorf("request body is not closed")
+			}
+		})
+	}
+}
+
+func TestFault_Latency(t *testing.T) {
+	fault := NewFault(1, FaultRule{Percentage: 100, Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	request := httptest.NewRequest(http.MethodGet, "http://example.com/", nil).WithContext(ctx)
+	_, err := fault.Middleware()(request, func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wrong error: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+// TransferStats describes the progress of the request or the response body transfer.
+type TransferStats struct {
+	// Request is the request of the transfer.
+	Request *http.Request
+	// Bytes is the number of the bytes transferred so far.
+	Bytes int64
+	// Total is the length of the body, -1 if it's unknown.
+	Total int64
+	// Elapsed is the time since the transfer start.
+	Elapsed time.Duration
+	// Rate is the average transfer rate in bytes per second.
+	Rate float64
+	// Done is set for the last report, after the body was read until the end or closed.
+	Done bool
+}
+
+// ProgressConfig is a configuration of the Progress middleware.
+type ProgressConfig struct {
+	// Upload is called with the progress of the request body.
+	Upload func(stats TransferStats)
+	// Download is called with the progress of the response body.
+	Download func(stats TransferStats)
+	// Interval is the minimal interval between the reports, zero reports after each read.
+	// The last report is always sent.
+	Interval time.Duration
+}
+
+// Progress is a chttp.Middleware constructor to report the number of the bytes transferred and the transfer rate
+// of the request and the response bodies. The request body is reported again, if it's resent on redirects or retries.
+func Progress(config ProgressConfig) chttp.Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		if config.Upload != nil {
+			total := request.ContentLength
+			if total == 0 {
+				total = -1
+			}
+			request = wrapRequestBody(request, func(body io.ReadCloser) io.ReadCloser {
+				return newProgressBody(body, request, total, config.Interval, config.Upload)
+			})
+		}
+		response, err := next(request)
+		if err != nil || config.Download == nil || response.Body == nil || response.Body == http.NoBody {
+			return response, err
+		}
+		response.Body = newProgressBody(response.Body, request, response.ContentLength, config.Interval, config.Download)
+		return response, nil
+	}
+}
+
+// wrapRequestBody returns the copy of the request with the wrapped body, including the bodies returned by
+// the http.Request GetBody. Requests without the body are returned as is.
+func wrapRequestBody(request *http.Request, wrap func(body io.ReadCloser) io.ReadCloser) *http.Request {
+	if request.Body == nil || request.Body == http.NoBody {
+		return request
+	}
+	wrapped := request.Clone(request.Context())
+	wrapped.Body = wrap(request.Body)
+	if request.GetBody != nil {
+		wrapped.GetBody = func() (io.ReadCloser, error) {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			return wrap(body), nil
+		}
+	}
+	return wrapped
+}
+
+type progressBody struct {
+	io.ReadCloser
+	mu       sync.Mutex
+	request  *http.Request
+	total    int64
+	interval time.Duration
+	report   func(stats TransferStats)
+	start    time.Time
+	reported time.Time
+	bytes    int64
+	done     bool
+}
+
+func newProgressBody(
+	body io.ReadCloser,
+	request *http.Request,
+	total int64,
+	interval time.Duration,
+	report func(stats TransferStats),
+) *progressBody {
+	if total < 0 {
+		total = -1
+	}
+	return &progressBody{
+		ReadCloser: body,
+		request:    request,
+		total:      total,
+		interval:   interval,
+		report:     report,
+		start:      time.Now(),
+	}
+}
+
+func (b *progressBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bytes += int64(n)
+	if err == io.EOF {
+		b.finish()
+	} else if n > 0 && time.Since(b.reported) >= b.interval {
+		b.send(false)
+	}
+	return n, err
+}
+
+func (b *progressBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.finish()
+	return err
+}
+
+func (b *progressBody) finish() {
+	if !b.done {
+		b.done = true
+		b.send(true)
+	}
+}
+
+func (b *progressBody) send(done bool) {
+	b.reported = time.Now()
+	elapsed := b.reported.Sub(b.start)
+	stats := TransferStats{
+		Request: b.request,
+		Bytes:   b.bytes,
+		Total:   b.total,
+		Elapsed: elapsed,
+		Done:    done,
+	}
+	if elapsed > 0 {
+		stats.Rate = float64(b.bytes) / elapsed.Seconds()
+	}
+	b.report(stats)
+}
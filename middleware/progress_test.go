@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+func ExampleProgress() {
+	client := chttp.NewClient(nil)
+	client.With(Progress(ProgressConfig{
+		Upload: func(stats TransferStats) {
+			_ = stats.Bytes * 100 / stats.Total
+		},
+		Interval: time.Second,
+	}))
+}
+
+func testEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, _ := io.ReadAll(request.Body)
+		writer.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(data)
+	}))
+}
+
+func TestProgress(t *testing.T) {
+	server := testEchoServer()
+	defer server.Close()
+	data := strings.Repeat("0123456789", 10000)
+
+	tests := []struct {
+		name   string
+		body   io.Reader
+		total  int64
+		length int64
+	}{
+		{name: "known length", body: strings.NewReader(data), total: int64(len(data)), length: int64(len(data))},
+		{name: "unknown length", body: io.MultiReader(strings.NewReader(data)), total: -1, length: int64(len(data))},
+		{name: "empty body", body: nil, total: 0, length: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				uploads  []TransferStats
+				download []TransferStats
+			)
+			client := chttp.NewClient(nil)
+			client.With(Progress(ProgressConfig{
+				Upload: func(stats TransferStats) {
+					mu.Lock()
+					defer mu.Unlock()
+					uploads = append(uploads, stats)
+				},
+				Download: func(stats TransferStats) {
+					download = append(download, stats)
+				},
+			}))
+			response, err := client.StreamRequest(context.Background(), http.MethodPost, server.URL, tt.body)
+			if err != nil {
+				t.Fatalf("POST() error = %v", err)
+			}
+			got, err := io.ReadAll(response.Body)
+			_ = response.Body.Close()
+			if err != nil || int64(len(got)) != tt.length {
+				t.Fatalf("ReadAll() = %d bytes, error = %v", len(got), err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.body == nil {
+				if len(uploads) != 0 {
+					t.Errorf("Progress() reported the empty upload: %v", uploads)
+				}
+			} else {
+				checkProgress(t, "upload", uploads, tt.length, tt.total)
+			}
+			if tt.length > 0 {
+				checkProgress(t, "download", download, tt.length, int64(len(data)))
+			}
+		})
+	}
+}
+
+func checkProgress(t *testing.T, name string, reports []TransferStats, length int64, total int64) {
+	t.Helper()
+	if len(reports) == 0 {
+		t.Fatalf("%s was not reported", name)
+	}
+	var previous int64
+	for i, stats := range reports {
+		if stats.Bytes < previous || stats.Total != total || stats.Request == nil || stats.Done != (i == len(reports)-1) {
+			t.Errorf("%s wrong report #%d: %+v", name, i, stats)
+		}
+		previous = stats.Bytes
+	}
+	if last := reports[len(reports)-1]; last.Bytes != length || last.Rate <= 0 {
+		t.Errorf("%s wrong last report: %+v", name, last)
+	}
+}
+
+func TestProgress_retry(t *testing.T) {
+	server := testEchoServer()
+	defer server.Close()
+	var totals []int64
+	client := chttp.NewClient(nil)
+	client.With(Progress(ProgressConfig{
+		Upload: func(stats TransferStats) {
+			if stats.Done {
+				totals = append(totals, stats.Bytes)
+			}
+		},
+	}))
+	attempt := 0
+	client.With(func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		_, _ = io.Copy(io.Discard, request.Body)
+		_ = request.Body.Close()
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt++
+		request.Body = body
+		return next(request)
+	})
+	response, err := client.StreamRequest(context.Background(), http.MethodPost, server.URL, bytes.NewReader([]byte("0123456789")))
+	if err != nil {
+		t.Fatalf("POST() error = %v", err)
+	}
+	_ = response.Body.Close()
+	if attempt != 1 || len(totals) != 2 || totals[0] != 10 || totals[1] != 10 {
+		t.Errorf("Progress() wrong reports: %v", totals)
+	}
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+// ThrottleScope defines which requests share the bandwidth limit of the Throttle middleware.
+type ThrottleScope int
+
+const (
+	// ThrottleGlobal shares the limit between all requests of the middleware.
+	ThrottleGlobal ThrottleScope = iota
+	// ThrottleHost shares the limit between the requests to the same host. The limiters are created on the first
+	// request to the host, and the limiters with the full buckets are removed while the number of the hosts grows,
+	// so the memory is bounded by the number of the recently active hosts.
+	ThrottleHost
+	// ThrottleRequest limits each request separately.
+	ThrottleRequest
+)
+
+// ThrottleConfig is a configuration of the Throttle middleware.
+type ThrottleConfig struct {
+	// Upload is the limit of the request bodies in bytes per second, zero means no limit.
+	Upload int64
+	// Download is the limit of the response bodies in bytes per second, zero means no limit.
+	Download int64
+	// Burst is the number of the bytes which could be transferred at once, the limit value by default.
+	Burst int64
+	// Scope defines which requests share the limit, ThrottleGlobal by default.
+	Scope ThrottleScope
+	// UploadLimiter and DownloadLimiter are the token buckets shared with other middlewares or clients.
+	// They replace the Upload and Download limits and ignore the Scope.
+	UploadLimiter   *Limiter
+	DownloadLimiter *Limiter
+}
+
+// Limiter is the token bucket, that limits the bandwidth in bytes per second. It's safe for the concurrent use
+// and could be shared between the Throttle middlewares.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates the Limiter with the given rate in bytes per second and the bucket size. The burst is equal
+// to the rate if it's not positive. Limiter with the zero rate doesn't limit anything.
+func NewLimiter(rate int64, burst int64) *Limiter {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &Limiter{
+		rate:   float64(rate),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// WaitN blocks until n bytes could be transferred or the context is done.
+func (l *Limiter) WaitN(ctx context.Context, n int64) error {
+	if l == nil || l.rate <= 0 || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// full reports whether the bucket is full, so the Limiter is equal to the new one.
+func (l *Limiter) full(now time.Time) bool {
+	if l == nil || l.rate <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tokens+now.Sub(l.last).Seconds()*l.rate >= float64(l.burst)
+}
+
+// chunk returns the maximal size of the single read.
+func (l *Limiter) chunk() int64 {
+	if l.burst <= 0 {
+		return 1
+	}
+	return l.burst
+}
+
+// Throttle is a chttp.Middleware constructor to limit the bandwidth of the request and the response bodies
+// with the token buckets. The bodies are slowed down while being read, so the limit also applies to the
+// requests resent on redirects or retries.
+func Throttle(config ThrottleConfig) chttp.Middleware {
+	global := throttleLimiters{upload: config.UploadLimiter, download: config.DownloadLimiter}
+	if global.upload == nil && config.Upload > 0 && config.Scope == ThrottleGlobal {
+		global.upload = NewLimiter(config.Upload, config.Burst)
+	}
+	if global.download == nil && config.Download > 0 && config.Scope == ThrottleGlobal {
+		global.download = NewLimiter(config.Download, config.Burst)
+	}
+	hosts := &throttleHosts{config: config, hosts: make(map[string]throttleLimiters), limit: throttleSweepSize}
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		limiters := global
+		switch config.Scope {
+		case ThrottleHost:
+			limiters.merge(hosts.get(requestHost(request)))
+		case ThrottleRequest:
+			limiters.merge(config.limiters())
+		}
+		if limiters.upload != nil {
+			request = wrapRequestBody(request, func(body io.ReadCloser) io.ReadCloser {
+				return &throttledBody{ReadCloser: body, ctx: request.Context(), limiter: limiters.upload}
+			})
+		}
+		response, err := next(request)
+		if err != nil || limiters.download == nil || response.Body == nil || response.Body == http.NoBody {
+			return response, err
+		}
+		response.Body = &throttledBody{ReadCloser: response.Body, ctx: request.Context(), limiter: limiters.download}
+		return response, nil
+	}
+}
+
+type throttleLimiters struct {
+	upload   *Limiter
+	download *Limiter
+}
+
+// merge sets the missing limiters.
+func (l *throttleLimiters) merge(limiters throttleLimiters) {
+	if l.upload == nil {
+		l.upload = limiters.upload
+	}
+	if l.download == nil {
+		l.download = limiters.download
+	}
+}
+
+// full reports whether all limiters have the full buckets.
+func (l throttleLimiters) full(now time.Time) bool {
+	return l.upload.full(now) && l.download.full(now)
+}
+
+// throttleSweepSize is the number of the hosts, after which the idle limiters are removed.
+const throttleSweepSize = 256
+
+// throttleHosts keeps the limiters of the hosts for the ThrottleHost scope.
+type throttleHosts struct {
+	mu     sync.RWMutex
+	config ThrottleConfig
+	hosts  map[string]throttleLimiters
+	limit  int
+}
+
+// get returns the limiters of the host, the limiters are created only for the new hosts.
+func (h *throttleHosts) get(host string) throttleLimiters {
+	h.mu.RLock()
+	limiters, ok := h.hosts[host]
+	h.mu.RUnlock()
+	if ok {
+		return limiters
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limiters, ok = h.hosts[host]; ok {
+		return limiters
+	}
+	if len(h.hosts) >= h.limit {
+		h.sweep()
+	}
+	limiters = h.config.limiters()
+	h.hosts[host] = limiters
+	return limiters
+}
+
+// sweep removes the limiters with the full buckets, as they are equal to the new ones, and doubles the limit
+// of the next sweep if there are still many active hosts.
+func (h *throttleHosts) sweep() {
+	now := time.Now()
+	for host, limiters := range h.hosts {
+		if limiters.full(now) {
+			delete(h.hosts, host)
+		}
+	}
+	h.limit = throttleSweepSize
+	if len(h.hosts)*2 > h.limit {
+		h.limit = len(h.hosts) * 2
+	}
+}
+
+func (c ThrottleConfig) limiters() throttleLimiters {
+	var limiters throttleLimiters
+	if c.Upload > 0 {
+		limiters.upload = NewLimiter(c.Upload, c.Burst)
+	}
+	if c.Download > 0 {
+		limiters.download = NewLimiter(c.Download, c.Burst)
+	}
+	return limiters
+}
+
+func requestHost(request *http.Request) string {
+	host := request.Host
+	if host == "" && request.URL != nil {
+		host = request.URL.Host
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+	return host
+}
+
+type throttledBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	limiter *Limiter
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if size := b.limiter.chunk(); int64(len(p)) > size {
+		p = p[:size]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if wErr := b.limiter.WaitN(b.ctx, int64(n)); wErr != nil && (err == nil || err == io.EOF) {
+		err = wErr
+	}
+	return n, err
+}
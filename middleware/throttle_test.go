@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+func ExampleThrottle() {
+	client := chttp.NewClient(nil)
+	client.With(Throttle(ThrottleConfig{
+		Upload: 1 << 20,
+		Scope:  ThrottleHost,
+	}))
+}
+
+func TestLimiter_WaitN(t *testing.T) {
+	limiter := NewLimiter(1000, 100)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.WaitN(context.Background(), 100); err != nil {
+			t.Fatalf("WaitN() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("WaitN() was not limited: %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.WaitN(ctx, 1000); !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitN() error = %v", err)
+	}
+	if err := NewLimiter(0, 0).WaitN(context.Background(), 1<<30); err != nil {
+		t.Errorf("WaitN() error = %v", err)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	server := testEchoServer()
+	defer server.Close()
+	data := strings.Repeat("0123456789", 100)
+
+	tests := []struct {
+		name     string
+		config   ThrottleConfig
+		requests int
+		min      time.Duration
+		max      time.Duration
+	}{
+		{
+			name:     "no limit",
+			config:   ThrottleConfig{},
+			requests: 2,
+			max:      200 * time.Millisecond,
+		},
+		{
+			name:     "global upload",
+			config:   ThrottleConfig{Upload: 4000, Burst: 400, Scope: ThrottleGlobal},
+			requests: 2,
+			min:      350 * time.Millisecond,
+		},
+		{
+			name:     "global download",
+			config:   ThrottleConfig{Download: 4000, Burst: 400, Scope: ThrottleGlobal},
+			requests: 2,
+			min:      350 * time.Millisecond,
+		},
+		{
+			name:     "per request",
+			config:   ThrottleConfig{Upload: 4000, Burst: 400, Scope: ThrottleRequest},
+			requests: 2,
+			min:      100 * time.Millisecond,
+			max:      300 * time.Millisecond,
+		},
+		{
+			name:     "per host",
+			config:   ThrottleConfig{Download: 4000, Burst: 400, Scope: ThrottleHost},
+			requests: 2,
+			min:      350 * time.Millisecond,
+		},
+		{
+			name:     "shared limiter",
+			config:   ThrottleConfig{DownloadLimiter: NewLimiter(4000, 400), Scope: ThrottleRequest},
+			requests: 2,
+			min:      350 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := chttp.NewClient(nil)
+			client.With(Throttle(tt.config))
+			var wg sync.WaitGroup
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					response, err := client.POST(context.Background(), server.URL, []byte(data))
+					if err != nil {
+						t.Errorf("POST() error = %v", err)
+						return
+					}
+					defer response.Body.Close()
+					if got, err := io.ReadAll(response.Body); err != nil || string(got) != data {
+						t.Errorf("ReadAll() = %d bytes, error = %v", len(got), err)
+					}
+				}()
+			}
+			wg.Wait()
+			elapsed := time.Since(start)
+			if elapsed < tt.min || (tt.max > 0 && elapsed > tt.max) {
+				t.Errorf("Throttle() elapsed = %v, want [%v, %v]", elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestThrottle_cancel(t *testing.T) {
+	server := testEchoServer()
+	defer server.Close()
+	client := chttp.NewClient(nil)
+	client.With(Throttle(ThrottleConfig{Download: 100, Scope: ThrottleRequest}))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	response, err := client.POST(ctx, server.URL, []byte(strings.Repeat("0123456789", 100)))
+	if err != nil {
+		t.Fatalf("POST() error = %v", err)
+	}
+	defer response.Body.Close()
+	if _, err = io.ReadAll(response.Body); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ReadAll() error = %v", err)
+	}
+}
+
+func TestThrottleHosts(t *testing.T) {
+	hosts := &throttleHosts{
+		config: ThrottleConfig{Download: 1000, Scope: ThrottleHost},
+		hosts:  make(map[string]throttleLimiters),
+		limit:  throttleSweepSize,
+	}
+	active := hosts.get("active")
+	if hosts.get("active") != active {
+		t.Errorf("get() created the limiters again")
+	}
+	if err := active.download.WaitN(context.Background(), 1000); err != nil {
+		t.Fatalf("WaitN() error = %v", err)
+	}
+	for i := 0; i < throttleSweepSize*2; i++ {
+		hosts.get(strconv.Itoa(i))
+	}
+	if len(hosts.hosts) > throttleSweepSize {
+		t.Errorf("get() keeps %d hosts", len(hosts.hosts))
+	}
+	if hosts.get("active") != active {
+		t.Errorf("get() removed the active limiters")
+	}
+}
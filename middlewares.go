@@ -0,0 +1,112 @@
+package chttp
+
+import (
+	"fmt"
+)
+
+// namedMiddleware is the entry of the Client middlewares list, the name is empty for the middlewares added
+// with the Client.With method.
+type namedMiddleware struct {
+	name       string
+	middleware Middleware
+}
+
+// Use appends the named Middleware to the end of the list, the name could be used later to insert other
+// middlewares around it, to remove, or to replace it. Returns ErrMiddlewareExists, if the name is already used.
+// Middleware with the empty name is anonymous, the same as added with the Client.With method.
+func (c *Client) Use(name string, middleware Middleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.insert(len(c.middlewares), name, middleware)
+}
+
+// Before inserts the named Middleware before the one with the target name, so it will be called earlier.
+// Returns ErrMiddlewareNotFound, if there is no target middleware.
+func (c *Client) Before(target string, name string, middleware Middleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.indexOf(target)
+	if err != nil {
+		return err
+	}
+	return c.insert(index, name, middleware)
+}
+
+// After inserts the named Middleware after the one with the target name, so it will be called later.
+// Returns ErrMiddlewareNotFound, if there is no target middleware.
+func (c *Client) After(target string, name string, middleware Middleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.indexOf(target)
+	if err != nil {
+		return err
+	}
+	return c.insert(index+1, name, middleware)
+}
+
+// Remove removes the Middleware with the given name. Returns ErrMiddlewareNotFound, if there is no such middleware.
+func (c *Client) Remove(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.indexOf(name)
+	if err != nil {
+		return err
+	}
+	middlewares := make([]namedMiddleware, 0, len(c.middlewares)-1)
+	middlewares = append(middlewares, c.middlewares[:index]...)
+	c.middlewares = append(middlewares, c.middlewares[index+1:]...)
+	return nil
+}
+
+// Replace replaces the Middleware with the given name keeping its position.
+// Returns ErrMiddlewareNotFound, if there is no such middleware.
+func (c *Client) Replace(name string, middleware Middleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	index, err := c.indexOf(name)
+	if err != nil {
+		return err
+	}
+	middlewares := make([]namedMiddleware, len(c.middlewares))
+	copy(middlewares, c.middlewares)
+	middlewares[index].middleware = middleware
+	c.middlewares = middlewares
+	return nil
+}
+
+// Middlewares returns the names of the middlewares in the order they are called,
+// anonymous middlewares are listed with the empty names.
+func (c *Client) Middlewares() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, len(c.middlewares))
+	for i, entry := range c.middlewares {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// insert inserts the middleware at the given position, the non-empty name should be unique.
+func (c *Client) insert(index int, name string, middleware Middleware) error {
+	if name != "" {
+		if _, err := c.indexOf(name); err == nil {
+			return fmt.Errorf("%w: %q", ErrMiddlewareExists, name)
+		}
+	}
+	middlewares := make([]namedMiddleware, 0, len(c.middlewares)+1)
+	middlewares = append(middlewares, c.middlewares[:index]...)
+	middlewares = append(middlewares, namedMiddleware{name: name, middleware: middleware})
+	c.middlewares = append(middlewares, c.middlewares[index:]...)
+	return nil
+}
+
+func (c *Client) indexOf(name string) (int, error) {
+	if name != "" {
+		for i, entry := range c.middlewares {
+			if entry.name == name {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrMiddlewareNotFound, name)
+}
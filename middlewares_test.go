@@ -0,0 +1,170 @@
+package chttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testNamedMiddleware(name string) Middleware {
+	return func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		request.Header.Add("X-Trace", name)
+		return next(request)
+	}
+}
+
+func TestClient_Use(t *testing.T) {
+	tests := []struct {
+		name    string
+		calls   string
+		setup   func(c *Client) error
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "use",
+			calls: "a,anonymous,b,c",
+			setup: func(c *Client) error {
+				return c.Use("c", testNamedMiddleware("c"))
+			},
+			want: []string{"a", "", "b", "c"},
+		},
+		{
+			name:  "use existing",
+			calls: "a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.Use("a", testNamedMiddleware("c"))
+			},
+			want:    []string{"a", "", "b"},
+			wantErr: ErrMiddlewareExists,
+		},
+		{
+			name:  "before",
+			calls: "c,a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.Before("a", "c", testNamedMiddleware("c"))
+			},
+			want: []string{"c", "a", "", "b"},
+		},
+		{
+			name:  "after",
+			calls: "a,c,anonymous,b",
+			setup: func(c *Client) error {
+				return c.After("a", "c", testNamedMiddleware("c"))
+			},
+			want: []string{"a", "c", "", "b"},
+		},
+		{
+			name:  "after last",
+			calls: "a,anonymous,b,c",
+			setup: func(c *Client) error {
+				return c.After("b", "c", testNamedMiddleware("c"))
+			},
+			want: []string{"a", "", "b", "c"},
+		},
+		{
+			name:  "before unknown",
+			calls: "a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.Before("unknown", "c", testNamedMiddleware("c"))
+			},
+			want:    []string{"a", "", "b"},
+			wantErr: ErrMiddlewareNotFound,
+		},
+		{
+			name:  "after anonymous",
+			calls: "a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.After("", "c", testNamedMiddleware("c"))
+			},
+			want:    []string{"a", "", "b"},
+			wantErr: ErrMiddlewareNotFound,
+		},
+		{
+			name:  "remove",
+			calls: "anonymous,b",
+			setup: func(c *Client) error {
+				return c.Remove("a")
+			},
+			want: []string{"", "b"},
+		},
+		{
+			name:  "remove unknown",
+			calls: "a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.Remove("c")
+			},
+			want:    []string{"a", "", "b"},
+			wantErr: ErrMiddlewareNotFound,
+		},
+		{
+			name:  "replace",
+			calls: "a,anonymous,c",
+			setup: func(c *Client) error {
+				return c.Replace("b", testNamedMiddleware("c"))
+			},
+			want: []string{"a", "", "b"},
+		},
+		{
+			name:  "replace unknown",
+			calls: "a,anonymous,b",
+			setup: func(c *Client) error {
+				return c.Replace("c", testNamedMiddleware("c"))
+			},
+			want:    []string{"a", "", "b"},
+			wantErr: ErrMiddlewareNotFound,
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Trace", strings.Join(request.Header.Values("X-Trace"), ","))
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(nil)
+			_ = client.Use("a", testNamedMiddleware("a"))
+			client.With(testNamedMiddleware("anonymous"))
+			_ = client.Use("b", testNamedMiddleware("b"))
+
+			err := tt.setup(client)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("setup error = %v, wantErr %v", err, tt.wantErr)
+			}
+			response, err := client.GET(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("GET() error = %v", err)
+			}
+			_ = response.Body.Close()
+			if got := response.Header.Get("X-Trace"); got != tt.calls {
+				t.Errorf("middlewares called = %q, want %q", got, tt.calls)
+			}
+			if names := client.Middlewares(); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Middlewares() = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Use_clone(t *testing.T) {
+	client := NewClient(nil)
+	_ = client.Use("auth", testNamedMiddleware("auth"))
+	_ = client.Use("trace", testNamedMiddleware("trace"))
+	clone := client.Clone()
+	if err := clone.Remove("auth"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := clone.Before("trace", "retry", testNamedMiddleware("retry")); err != nil {
+		t.Fatalf("Before() error = %v", err)
+	}
+	if got := client.Middlewares(); !reflect.DeepEqual(got, []string{"auth", "trace"}) {
+		t.Errorf("Middlewares() = %q", got)
+	}
+	if got := clone.Middlewares(); !reflect.DeepEqual(got, []string{"retry", "trace"}) {
+		t.Errorf("Middlewares() = %q", got)
+	}
+}
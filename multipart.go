@@ -0,0 +1,81 @@
+package chttp
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// Multipart is a `multipart/form-data` request body for the FormClient.
+// Files are streamed from the given readers through the io.Pipe, so the body is never buffered in memory.
+// Multipart body could be sent only once.
+type Multipart struct {
+	parts []multipartPart
+}
+
+type multipartPart struct {
+	header textproto.MIMEHeader
+	value  string
+	reader io.Reader
+}
+
+// NewMultipart creates an empty Multipart body.
+func NewMultipart() *Multipart {
+	return &Multipart{}
+}
+
+// Field adds the form field.
+func (m *Multipart) Field(name string, value string) *Multipart {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(name)))
+	m.parts = append(m.parts, multipartPart{header: header, value: value})
+	return m
+}
+
+// File adds the file, which content will be streamed from the reader.
+// Content type of the file is `application/octet-stream` by default.
+func (m *Multipart) File(field string, filename string, content io.Reader, contentType ...string) *Multipart {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(field), escapeQuotes(filename)))
+	header.Set("Content-Type", "application/octet-stream")
+	if len(contentType) > 0 {
+		header.Set("Content-Type", contentType[0])
+	}
+	m.parts = append(m.parts, multipartPart{header: header, reader: content})
+	return m
+}
+
+// reader returns the body reader and its content type with the boundary.
+func (m *Multipart) reader() (io.ReadCloser, string) {
+	boundary := multipart.NewWriter(nil).Boundary()
+	return newPipeReader(func(w io.Writer) error {
+		writer := multipart.NewWriter(w)
+		if err := writer.SetBoundary(boundary); err != nil {
+			return err
+		}
+		for _, part := range m.parts {
+			content, err := writer.CreatePart(part.header)
+			if err != nil {
+				return err
+			}
+			if part.reader != nil {
+				_, err = io.Copy(content, part.reader)
+			} else {
+				_, err = io.WriteString(content, part.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return writer.Close()
+	}), "multipart/form-data; boundary=" + boundary
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,113 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMultipart(t *testing.T) {
+	const size = 4 << 20
+	content := io.LimitReader(rand.New(rand.NewSource(1)), size)
+	hash := sha256.New()
+	content = io.TeeReader(content, hash)
+
+	type part struct {
+		Name        string `json:"name"`
+		Filename    string `json:"filename"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+		Hash        string `json:"hash"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mediaType, params, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+			t.Errorf("wrong content type: %s", request.Header.Get("Content-Type"))
+		}
+		reader, err := request.MultipartReader()
+		if err != nil {
+			t.Errorf("MultipartReader() error = %v", err)
+			return
+		}
+		parts := make([]part, 0)
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("NextPart() error = %v", err)
+				return
+			}
+			hash := sha256.New()
+			size, _ := io.Copy(hash, p)
+			parts = append(parts, part{
+				Name:        p.FormName(),
+				Filename:    p.FileName(),
+				ContentType: p.Header.Get("Content-Type"),
+				Size:        size,
+				Hash:        hex.EncodeToString(hash.Sum(nil)),
+			})
+		}
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write(mustMarshal(t, parts))
+	}))
+	defer server.Close()
+
+	var result []part
+	err := NewForm(nil).POST(context.Background(), server.URL, NewMultipart().
+		Field("name", `dog "Rex"`).
+		File("image", "rex.png", content, "image/png").
+		File("notes", "notes.txt", strings.NewReader("notes")), &result)
+	if err != nil {
+		t.Errorf("POST() error = %v", err)
+		return
+	}
+	if len(result) != 3 {
+		t.Errorf("wrong parts: %v", result)
+		return
+	}
+	if result[0].Name != "name" || result[0].Filename != "" || result[0].Size != int64(len(`dog "Rex"`)) {
+		t.Errorf("wrong field part: %+v", result[0])
+	}
+	if result[1].Name != "image" || result[1].Filename != "rex.png" || result[1].ContentType != "image/png" ||
+		result[1].Size != size || result[1].Hash != hex.EncodeToString(hash.Sum(nil)) {
+		t.Errorf("wrong image part: %+v", result[1])
+	}
+	if result[2].Name != "notes" || result[2].ContentType != "application/octet-stream" || result[2].Size != 5 {
+		t.Errorf("wrong notes part: %+v", result[2])
+	}
+}
+
+func TestMultipart_readerError(t *testing.T) {
+	reader, _ := NewMultipart().File("file", "file", io.MultiReader(
+		bytes.NewReader([]byte("data")),
+		errorReader{err: io.ErrUnexpectedEOF},
+	)).reader()
+	if _, err := io.ReadAll(reader); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadAll() error = %v", err)
+	}
+	closed, _ := NewMultipart().Field("a", "b").reader()
+	if err := closed.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if _, err := closed.Read(make([]byte, 1)); err != io.ErrClosedPipe {
+		t.Errorf("Read() error = %v", err)
+	}
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
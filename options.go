@@ -1,7 +1,9 @@
 package chttp
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -34,3 +36,59 @@ func WithCheckRedirect(check func(req *http.Request, via []*http.Request) error)
 		c.HTTP.CheckRedirect = check
 	}
 }
+
+// WithBaseURL set the base URL for the relative URLs of the requests.
+// The base path prefix is preserved, e.g. the relative URL `pet/1` with the base URL `https://example.com/api/v3`
+// will be resolved to the `https://example.com/api/v3/pet/1`.
+func WithBaseURL(base string) Option {
+	return func(c *Client) {
+		uri, err := url.Parse(base)
+		if err == nil && (!uri.IsAbs() || uri.Host == "") {
+			err = fmt.Errorf("base URL should be absolute: %q", base)
+		}
+		if err != nil {
+			c.baseURL, c.baseURLErr = nil, fmt.Errorf("invalid base URL: %w", err)
+			return
+		}
+		c.baseURL, c.baseURLErr = uri, nil
+	}
+}
+
+// WithMaxResponseSize set the maximum size of the response body decoded by the JSONClient, zero means no limit.
+// Exceeding the limit results in the *Error with the ErrResponseTooLarge base error.
+// The body of the unsuccessful response is read into the Error.Body up to the same limit, but not more than 64 KiB.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = size
+	}
+}
+
+// WithJSONConfig set the configuration of the JSON encoding and decoding of the request and response bodies:
+// strict decoding, numbers as json.Number, HTML escaping, indentation, or the custom Marshal and Unmarshal functions.
+func WithJSONConfig(config JSONConfig) Option {
+	return func(c *Client) {
+		c.jsonConfig = &config
+	}
+}
+
+// WithProblem registers the constructor of the Go error for the problem type URI for the client only,
+// see the RegisterProblem for the details. The client registrations take precedence over the global ones,
+// nil constructor disables the global registration of the type for the client.
+func WithProblem(typeURI string, constructor func(problem *Problem) error) Option {
+	return func(c *Client) {
+		if c.problems == nil {
+			c.problems = make(map[string]func(problem *Problem) error)
+		}
+		c.problems[typeURI] = constructor
+	}
+}
+
+// WithStatusPolicy set the StatusPolicy of the client, which describes the successful status codes and how
+// the response bodies are decoded. The policy could be overridden for the single call with the ContextWithStatusPolicy.
+// The StatusPolicy.Targets are ignored and never merged with the targets of the single call, set them
+// for the single call only.
+func WithStatusPolicy(policy StatusPolicy) Option {
+	return func(c *Client) {
+		c.policy = &policy
+	}
+}
@@ -0,0 +1,345 @@
+package chttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+)
+
+// Pagination describes how to iterate over the items of the paged endpoint.
+type Pagination[Page any, Item any] struct {
+	// Strategy computes the url of the next page. See LinkNext, Cursor, OffsetLimit, and PageNumber.
+	Strategy PageStrategy[Page]
+	// Items extracts the items from the page. Could be omitted if the Page is the []Item.
+	Items func(page Page) []Item
+	// MaxPages is the maximal number of the requested pages. If there are more pages, the iteration is finished
+	// with the ErrTooManyPages error. Zero means no limit.
+	MaxPages int
+	// Prefetch enables requesting the next page while the items of the current one are processed.
+	Prefetch bool
+}
+
+// PageStrategy computes the urls of the pages.
+type PageStrategy[Page any] interface {
+	// First returns the url of the first page based on the given one.
+	First(url string) (string, error)
+	// Next returns the url of the next page, or the empty string if the page is the last one.
+	Next(page PageInfo[Page]) (string, error)
+}
+
+// PageInfo is the received page with the request details.
+type PageInfo[Page any] struct {
+	// URL is the requested url of the page.
+	URL string
+	// Number is the sequence number of the page, starting from 1.
+	Number int
+	// Response is the response of the page, the body is already closed.
+	Response *http.Response
+	// Page is the unmarshalled response body.
+	Page Page
+	// Items is the number of the items on the page.
+	Items int
+}
+
+type pageResult[Page any] struct {
+	page     Page
+	response *http.Response
+	err      error
+}
+
+// Paginate returns the iterator over the items of all pages of the paged endpoint, which could be used
+// with the range-over-func loop. Pages are requested with the http.MethodGet method. The error is yielded once
+// as the last element.
+//
+//	items := chttp.Paginate(ctx, client, "/pets?limit=100", chttp.Pagination[[]Pet, Pet]{
+//		Strategy: chttp.LinkNext[[]Pet](),
+//		MaxPages: 50,
+//	})
+//	for pet, err := range items {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func Paginate[Page any, Item any](
+	ctx context.Context,
+	client *GenericJSONClient[Page],
+	url string,
+	pagination Pagination[Page, Item],
+) func(yield func(Item, error) bool) {
+	return func(yield func(Item, error) bool) {
+		var zero Item
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		if pagination.Strategy == nil {
+			yield(zero, fmt.Errorf("pagination: Strategy is not set"))
+			return
+		}
+		url, err := pagination.Strategy.First(url)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		pending := fetchPage(ctx, client, url)
+		for number := 1; ; number++ {
+			current := <-pending
+			if current.err != nil {
+				yield(zero, current.err)
+				return
+			}
+			items, err := pagination.items(current.page)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			next, err := pagination.Strategy.Next(PageInfo[Page]{
+				URL:      url,
+				Number:   number,
+				Response: current.response,
+				Page:     current.page,
+				Items:    len(items),
+			})
+			limited := pagination.MaxPages > 0 && number >= pagination.MaxPages
+			pending = nil
+			if err == nil && next != "" && !limited && pagination.Prefetch {
+				pending = fetchPage(ctx, client, next)
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if next == "" {
+				return
+			}
+			if limited {
+				yield(zero, fmt.Errorf("%w: %d", ErrTooManyPages, pagination.MaxPages))
+				return
+			}
+			url = next
+			if pending == nil {
+				pending = fetchPage(ctx, client, url)
+			}
+		}
+	}
+}
+
+func (p Pagination[Page, Item]) items(page Page) ([]Item, error) {
+	if p.Items != nil {
+		return p.Items(page), nil
+	}
+	if items, ok := interface{}(page).([]Item); ok {
+		return items, nil
+	}
+	return nil, fmt.Errorf("pagination: Items function is not set")
+}
+
+// fetchPage requests the page in the background, the result channel is buffered to not block on cancellation.
+// The response is decoded with the JSONClient.UnmarshalHTTPResponse, so the errors are decoded like in the verbs.
+func fetchPage[Page any](ctx context.Context, client *GenericJSONClient[Page], url string) <-chan pageResult[Page] {
+	result := make(chan pageResult[Page], 1)
+	go func() {
+		var page pageResult[Page]
+		response, err := client.Client.request(ctx, http.MethodGet, url, nil, nil)
+		page.response = response
+		page.err = client.JSONClient.UnmarshalHTTPResponse(response, err, &page.page)
+		result <- page
+	}()
+	return result
+}
+
+type linkNext[Page any] struct{}
+
+// LinkNext is the PageStrategy, which follows the `Link` header with the `rel="next"` relation (RFC 8288).
+// Relative links are resolved against the url of the current page.
+func LinkNext[Page any]() PageStrategy[Page] {
+	return linkNext[Page]{}
+}
+
+func (linkNext[Page]) First(url string) (string, error) {
+	return url, nil
+}
+
+func (linkNext[Page]) Next(page PageInfo[Page]) (string, error) {
+	if page.Response == nil {
+		return "", nil
+	}
+	next := findLink(page.Response.Header.Values("Link"), "next")
+	if next == "" {
+		return "", nil
+	}
+	ref, err := neturl.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("pagination: invalid next link: %w", err)
+	}
+	if page.Response.Request != nil && page.Response.Request.URL != nil {
+		return page.Response.Request.URL.ResolveReference(ref).String(), nil
+	}
+	return ref.String(), nil
+}
+
+type cursor[Page any] struct {
+	param  string
+	cursor func(page Page) string
+}
+
+// Cursor is the PageStrategy, which sets the cursor token extracted from the page to the query parameter.
+// The iteration is finished when the cursor is empty.
+func Cursor[Page any](param string, token func(page Page) string) PageStrategy[Page] {
+	return cursor[Page]{param: param, cursor: token}
+}
+
+func (c cursor[Page]) First(url string) (string, error) {
+	return url, nil
+}
+
+func (c cursor[Page]) Next(page PageInfo[Page]) (string, error) {
+	token := c.cursor(page.Page)
+	if token == "" {
+		return "", nil
+	}
+	return setQuery(page.URL, c.param, token)
+}
+
+type offsetLimit[Page any] struct {
+	offset string
+	limit  string
+	size   int
+}
+
+// OffsetLimit is the PageStrategy, which sets the offset and limit query parameters. The initial offset is taken
+// from the url, zero by default. The iteration is finished when the page contains less items than the limit.
+func OffsetLimit[Page any](offsetParam string, limitParam string, limit int) PageStrategy[Page] {
+	return offsetLimit[Page]{offset: offsetParam, limit: limitParam, size: limit}
+}
+
+func (o offsetLimit[Page]) First(url string) (string, error) {
+	if o.size <= 0 {
+		return "", fmt.Errorf("pagination: invalid limit %d", o.size)
+	}
+	return setQuery(url, o.limit, strconv.Itoa(o.size))
+}
+
+func (o offsetLimit[Page]) Next(page PageInfo[Page]) (string, error) {
+	if page.Items == 0 || page.Items < o.size {
+		return "", nil
+	}
+	offset, err := queryInt(page.URL, o.offset, 0)
+	if err != nil {
+		return "", err
+	}
+	return setQuery(page.URL, o.offset, strconv.Itoa(offset+page.Items))
+}
+
+type pageNumber[Page any] struct {
+	param string
+	size  int
+}
+
+// PageNumber is the PageStrategy, which increments the page number query parameter. The initial number is taken
+// from the url, 1 by default. The iteration is finished when the page is empty or contains less items than
+// the page size, zero page size means unknown size.
+func PageNumber[Page any](param string, pageSize int) PageStrategy[Page] {
+	return pageNumber[Page]{param: param, size: pageSize}
+}
+
+func (p pageNumber[Page]) First(url string) (string, error) {
+	return url, nil
+}
+
+func (p pageNumber[Page]) Next(page PageInfo[Page]) (string, error) {
+	if page.Items == 0 || page.Items < p.size {
+		return "", nil
+	}
+	number, err := queryInt(page.URL, p.param, 1)
+	if err != nil {
+		return "", err
+	}
+	return setQuery(page.URL, p.param, strconv.Itoa(number+1))
+}
+
+// findLink returns the target of the first link with the given relation from the `Link` header values.
+func findLink(values []string, relation string) string {
+	for _, value := range values {
+		for len(value) > 0 {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			value = value[end+1:]
+			params := value
+			if next := indexUnquoted(value, ','); next >= 0 {
+				params, value = value[:next], value[next+1:]
+			} else {
+				value = ""
+			}
+			if hasRelation(params, relation) {
+				return target
+			}
+		}
+	}
+	return ""
+}
+
+func hasRelation(params string, relation string) bool {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+			continue
+		}
+		for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+			if strings.EqualFold(rel, relation) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func indexUnquoted(value string, char byte) int {
+	quoted := false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			quoted = !quoted
+		case char:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func setQuery(url string, name string, value string) (string, error) {
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return "", err
+	}
+	query := uri.Query()
+	query.Set(name, value)
+	uri.RawQuery = query.Encode()
+	return uri.String(), nil
+}
+
+func queryInt(url string, name string, value int) (int, error) {
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return 0, err
+	}
+	if raw := uri.Query().Get(name); raw != "" {
+		if value, err = strconv.Atoi(raw); err != nil {
+			return 0, fmt.Errorf("pagination: invalid %s query parameter: %w", name, err)
+		}
+	}
+	return value, nil
+}
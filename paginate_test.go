@@ -0,0 +1,293 @@
+package chttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+type testPage struct {
+	Items []int  `json:"items"`
+	Next  string `json:"next,omitempty"`
+}
+
+// testServerPages serves 10 items [1..10] with one of the pagination schemes, depending on the path.
+func testServerPages(t *testing.T, requests *int32) *httptest.Server {
+	const total = 10
+	items := func(offset int, limit int) []int {
+		result := make([]int, 0, limit)
+		for i := offset; i < offset+limit && i < total; i++ {
+			result = append(result, i+1)
+		}
+		return result
+	}
+	atoi := func(value string, def int) int {
+		if value == "" {
+			return def
+		}
+		result, err := strconv.Atoi(value)
+		if err != nil {
+			t.Errorf("Atoi() error = %v", err)
+		}
+		return result
+	}
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(requests, 1)
+		query := request.URL.Query()
+		var body interface{}
+		switch request.URL.Path {
+		case "/link":
+			page := atoi(query.Get("page"), 0)
+			if (page+1)*3 < total {
+				writer.Header().Add("Link", `<https://example.com/first>; rel="first"`)
+				writer.Header().Add("Link", fmt.Sprintf(`</link?page=%d>; rel="last next"`, page+1))
+			}
+			body = items(page*3, 3)
+		case "/cursor":
+			offset := atoi(query.Get("cursor"), 0)
+			page := testPage{Items: items(offset, 4)}
+			if offset+4 < total {
+				page.Next = strconv.Itoa(offset + 4)
+			}
+			body = page
+		case "/offset":
+			body = items(atoi(query.Get("offset"), 0), atoi(query.Get("limit"), 0))
+		case "/page":
+			body = items((atoi(query.Get("page"), 1)-1)*4, 4)
+		case "/error":
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(writer).Encode(body)
+	}))
+}
+
+func collectItems(items func(yield func(int, error) bool)) (result []int, err error) {
+	items(func(item int, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		result = append(result, item)
+		return true
+	})
+	return result, err
+}
+
+func TestPaginate(t *testing.T) {
+	all := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name         string
+		path         string
+		pagination   Pagination[[]int, int]
+		want         []int
+		wantRequests int32
+		wantErr      error
+	}{
+		{
+			name:         "link",
+			path:         "/link",
+			pagination:   Pagination[[]int, int]{Strategy: LinkNext[[]int]()},
+			want:         all,
+			wantRequests: 4,
+		},
+		{
+			name:         "offset",
+			path:         "/offset",
+			pagination:   Pagination[[]int, int]{Strategy: OffsetLimit[[]int]("offset", "limit", 5)},
+			want:         all,
+			wantRequests: 3,
+		},
+		{
+			name:         "offset from url",
+			path:         "/offset?offset=6",
+			pagination:   Pagination[[]int, int]{Strategy: OffsetLimit[[]int]("offset", "limit", 3)},
+			want:         []int{7, 8, 9, 10},
+			wantRequests: 2,
+		},
+		{
+			name:         "page number",
+			path:         "/page",
+			pagination:   Pagination[[]int, int]{Strategy: PageNumber[[]int]("page", 4)},
+			want:         all,
+			wantRequests: 3,
+		},
+		{
+			name:         "page number without size",
+			path:         "/page?page=2",
+			pagination:   Pagination[[]int, int]{Strategy: PageNumber[[]int]("page", 0)},
+			want:         []int{5, 6, 7, 8, 9, 10},
+			wantRequests: 3,
+		},
+		{
+			name:         "max pages",
+			path:         "/link",
+			pagination:   Pagination[[]int, int]{Strategy: LinkNext[[]int](), MaxPages: 2},
+			want:         []int{1, 2, 3, 4, 5, 6},
+			wantRequests: 2,
+			wantErr:      ErrTooManyPages,
+		},
+		{
+			name:         "prefetch",
+			path:         "/link",
+			pagination:   Pagination[[]int, int]{Strategy: LinkNext[[]int](), Prefetch: true},
+			want:         all,
+			wantRequests: 4,
+		},
+		{
+			name:         "prefetch with max pages",
+			path:         "/link",
+			pagination:   Pagination[[]int, int]{Strategy: LinkNext[[]int](), Prefetch: true, MaxPages: 1},
+			want:         []int{1, 2, 3},
+			wantRequests: 1,
+			wantErr:      ErrTooManyPages,
+		},
+		{
+			name:         "error",
+			path:         "/error",
+			pagination:   Pagination[[]int, int]{Strategy: LinkNext[[]int]()},
+			want:         nil,
+			wantRequests: 1,
+			wantErr:      ErrStatusCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := testServerPages(t, &requests)
+			defer server.Close()
+
+			client := NewGenericJSONClient[[]int](NewJSON(nil, WithBaseURL(server.URL)))
+			got, err := collectItems(Paginate(context.Background(), client, tt.path, tt.pagination))
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Errorf("Paginate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Paginate() got = %v, want %v", got, tt.want)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("Paginate() requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPaginate_cursor(t *testing.T) {
+	var requests int32
+	server := testServerPages(t, &requests)
+	defer server.Close()
+
+	client := NewGenericJSONClient[testPage](NewJSON(nil))
+	items := Paginate(context.Background(), client, server.URL+"/cursor", Pagination[testPage, int]{
+		Strategy: Cursor("cursor", func(page testPage) string {
+			return page.Next
+		}),
+		Items: func(page testPage) []int {
+			return page.Items
+		},
+	})
+	got, err := collectItems(items)
+	if err != nil {
+		t.Errorf("Paginate() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("Paginate() got = %v", got)
+	}
+
+	_, err = collectItems(Paginate(context.Background(), client, server.URL+"/cursor", Pagination[testPage, int]{
+		Strategy: LinkNext[testPage](),
+	}))
+	if err == nil {
+		t.Errorf("Paginate() error wanted without the Items function")
+	}
+}
+
+func TestPaginate_break(t *testing.T) {
+	var requests int32
+	server := testServerPages(t, &requests)
+	defer server.Close()
+
+	client := NewGenericJSONClient[[]int](NewJSON(nil))
+	var got []int
+	Paginate(context.Background(), client, server.URL+"/link", Pagination[[]int, int]{
+		Strategy: LinkNext[[]int](),
+	})(func(item int, err error) bool {
+		got = append(got, item)
+		return len(got) < 4
+	})
+	if !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Paginate() got = %v", got)
+	}
+	if requests != 2 {
+		t.Errorf("Paginate() requests = %d", requests)
+	}
+}
+
+func TestPaginate_errors(t *testing.T) {
+	type apiError struct {
+		Message string `json:"message"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"message":"bad cursor"}`))
+	}))
+	defer server.Close()
+
+	client := NewGenericJSONErrorClient[[]int, apiError](NewJSON(nil))
+	tests := []struct {
+		name       string
+		pagination Pagination[[]int, int]
+		check      func(err error) bool
+	}{
+		{
+			name:       "strategy is not set",
+			pagination: Pagination[[]int, int]{},
+			check: func(err error) bool {
+				return err != nil && err.Error() == "pagination: Strategy is not set"
+			},
+		},
+		{
+			name:       "decoded error",
+			pagination: Pagination[[]int, int]{Strategy: LinkNext[[]int]()},
+			check: func(err error) bool {
+				apiErr := new(ResponseError[apiError])
+				return errors.As(err, &apiErr) && apiErr.Value.Message == "bad cursor"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := collectItems(Paginate(context.Background(), &client.GenericJSONClient, server.URL, tt.pagination))
+			if !tt.check(err) {
+				t.Errorf("Paginate() wrong error = %v", err)
+			}
+		})
+	}
+}
+
+func TestFindLink(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{values: nil, want: ""},
+		{values: []string{`<https://example.com/2>; rel="next"`}, want: "https://example.com/2"},
+		{values: []string{`<https://example.com/1>; rel=prev, <https://example.com/3>; rel=next`}, want: "https://example.com/3"},
+		{values: []string{`<https://example.com/a,b>; title="a, rel=next"; rel="prev"`}, want: ""},
+		{values: []string{`<https://example.com/a,b>; title="a, b"; rel="prev next"`}, want: "https://example.com/a,b"},
+		{values: []string{`<https://example.com/1>; rel="prev"`, `</2>; REL="Next"`}, want: "/2"},
+	}
+	for _, tt := range tests {
+		if got := findLink(tt.values, "next"); got != tt.want {
+			t.Errorf("findLink(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package chttp
+
+import (
+	"encoding"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+	xmlNameType       = reflect.TypeOf(xml.Name{})
+)
+
+// requestParams is a list of the request parameters lifted from the request structure fields
+// tagged with the `path`, `query`, and `header` tags.
+type requestParams struct {
+	path   map[string]interface{}
+	names  map[string]bool
+	query  neturl.Values
+	header http.Header
+}
+
+func newRequestParams() *requestParams {
+	return &requestParams{
+		path:   make(map[string]interface{}),
+		names:  make(map[string]bool),
+		query:  make(neturl.Values),
+		header: make(http.Header),
+	}
+}
+
+// splitParams lifts the tagged fields of the body structure into the requestParams and returns the remainder
+// of the body, which should be marshaled as the request body. Supported tag options:
+//
+//	`path:"name"`               - path parameter, replaces the `{name}` expression (RFC 6570) in the url,
+//	                              the url should use it;
+//	`query:"name,omitempty"`    - query parameter, slices are repeated: `name=a&name=b`;
+//	`query:"name,comma"`        - query parameter, slices are joined with comma: `name=a,b`;
+//	`header:"Name,omitempty"`   - header value, slices are added as separate values;
+//	`layout:"2006-01-02"`       - time.Time format layout, time.RFC3339 by default.
+//
+// Pointers, time.Time, encoding.TextMarshaler, and basic types are supported, nil pointers are omitted.
+// If no fields are tagged, the body is returned as is, otherwise the remainder is the copy of the structure
+// without the lifted fields, so it could be encoded with any Codec.
+func splitParams(body interface{}) (interface{}, *requestParams, error) {
+	value := reflect.ValueOf(body)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct || value.Type() == timeType {
+		return body, nil, nil
+	}
+	params := newRequestParams()
+	hasBody, lifted, err := params.collect(value)
+	if err != nil {
+		return nil, nil, NewError(nil, nil, fmt.Errorf("encoding request parameters error: %w", err))
+	}
+	if !lifted {
+		return body, nil, nil
+	}
+	if !hasBody {
+		return nil, params, nil
+	}
+	return bodyValue(value), params, nil
+}
+
+// collect walks through the structure fields and collects all tagged values.
+// Returns whether the structure has the fields, which should be marshaled as the body, and the tagged fields.
+func (p *requestParams) collect(value reflect.Value) (hasBody bool, lifted bool, err error) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		jsonName, _ := parseTag(field.Tag.Get("json"))
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		kind, name, options := paramTag(field.Tag)
+		if kind == "" || !field.IsExported() {
+			if jsonName == "-" {
+				continue
+			}
+			if embedded, ok := embeddedStruct(field, value.Field(i)); ok && jsonName == "" {
+				fieldHasBody, fieldLifted, err := p.collect(embedded)
+				if err != nil {
+					return false, false, err
+				}
+				hasBody = hasBody || fieldHasBody
+				lifted = lifted || fieldLifted
+			} else {
+				hasBody = true
+			}
+			continue
+		}
+		lifted = true
+		if kind == "path" {
+			p.names[name] = true
+		}
+		values, ok, err := encodeParam(value.Field(i), field.Tag.Get("layout"))
+		if err != nil {
+			return false, false, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if !ok || (options["omitempty"] && isEmptyParam(value.Field(i))) {
+			continue
+		}
+		switch kind {
+		case "path":
+			p.path[name] = values
+		case "query":
+			if options["comma"] {
+				values = []string{strings.Join(values, ",")}
+			}
+			p.query[name] = append(p.query[name], values...)
+		case "header":
+			for _, item := range values {
+				p.header.Add(name, item)
+			}
+		}
+	}
+	return hasBody, lifted, nil
+}
+
+// buildURL applies the path and query parameters to the url.
+func (p *requestParams) buildURL(url string) (string, error) {
+	if p == nil {
+		return url, nil
+	}
+	url, err := p.expandPath(url)
+	if err != nil {
+		return "", err
+	}
+	if len(p.query) == 0 {
+		return url, nil
+	}
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return "", err
+	}
+	query := uri.Query()
+	for name, values := range p.query {
+		query[name] = append(query[name], values...)
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String(), nil
+}
+
+// expandPath expands the url template with the path parameters. Returns an error if a path parameter is not
+// used by the template, or a simple `{name}` expression has no path parameter. The "." and ".." values
+// of the simple and path segment expressions are escaped, so they are not resolved as the dot segments.
+func (p *requestParams) expandPath(url string) (string, error) {
+	var (
+		template strings.Builder
+		used     = make(map[string]bool, len(p.names))
+		rest     = url
+	)
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		expression := rest[start : start+end+1]
+		template.WriteString(rest[:start])
+		rest = rest[start+end+1:]
+
+		body := expression[1 : len(expression)-1]
+		simple := body != "" && strings.IndexByte("+#./;?&=,!@|", body[0]) < 0
+		if !simple && body != "" {
+			body = body[1:]
+		}
+		variables, err := parseTemplateVariables(body)
+		if err != nil {
+			// Template reports the invalid expression.
+			template.WriteString(expression)
+			continue
+		}
+		for _, variable := range variables {
+			used[variable.name] = true
+			if simple && !p.names[variable.name] {
+				return "", fmt.Errorf("path parameter %s is not set", expression)
+			}
+		}
+		switch {
+		case simple && len(variables) == 1:
+			if segment, ok := p.dotSegment(variables[0].name); ok {
+				template.WriteString(escapeSegment(segment))
+				continue
+			}
+		case expression[1] == '/':
+			// Every variable of the path segment expression is expanded as a separate segment.
+			specs := strings.Split(body, ",")
+			for i, variable := range variables {
+				if segment, ok := p.dotSegment(variable.name); ok {
+					template.WriteString("/" + escapeSegment(segment))
+				} else {
+					template.WriteString("{/" + specs[i] + "}")
+				}
+			}
+			continue
+		}
+		template.WriteString(expression)
+	}
+	template.WriteString(rest)
+	for name := range p.names {
+		if !used[name] {
+			return "", fmt.Errorf("path parameter %q is not used in the url", name)
+		}
+	}
+	if len(p.names) == 0 {
+		return url, nil
+	}
+	return Template(template.String(), p.path)
+}
+
+// dotSegment returns the path parameter value if it is the "." or ".." dot segment.
+func (p *requestParams) dotSegment(name string) (string, bool) {
+	values, ok := p.path[name].([]string)
+	if !ok || len(values) != 1 || (values[0] != "." && values[0] != "..") {
+		return "", false
+	}
+	return values[0], true
+}
+
+// apply sets the header parameters to the request.
+func (p *requestParams) apply(request *http.Request) {
+	if p == nil {
+		return
+	}
+	for name, values := range p.header {
+		request.Header[name] = values
+	}
+}
+
+func paramTag(tag reflect.StructTag) (kind string, name string, options map[string]bool) {
+	for _, kind = range [...]string{"path", "query", "header"} {
+		if value, ok := tag.Lookup(kind); ok {
+			name, options = parseTag(value)
+			if name != "" && name != "-" {
+				return kind, name, options
+			}
+		}
+	}
+	return "", "", nil
+}
+
+func parseTag(tag string) (name string, options map[string]bool) {
+	parts := strings.Split(tag, ",")
+	options = make(map[string]bool, len(parts)-1)
+	for _, option := range parts[1:] {
+		options[option] = true
+	}
+	return parts[0], options
+}
+
+func embeddedStruct(field reflect.StructField, value reflect.Value) (reflect.Value, bool) {
+	if !field.Anonymous {
+		return value, false
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return value, false
+		}
+		value = value.Elem()
+	}
+	return value, value.Kind() == reflect.Struct && value.Type() != timeType
+}
+
+// encodeParam encodes the value into the list of strings, returns false if the value is nil.
+func encodeParam(value reflect.Value, layout string) ([]string, bool, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, false, nil
+		}
+		value = value.Elem()
+	}
+	if value.Type() == timeType {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return []string{value.Interface().(time.Time).Format(layout)}, true, nil
+	}
+	if marshaler, ok := textMarshaler(value); ok {
+		data, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, false, err
+		}
+		return []string{string(data)}, true, nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return []string{value.String()}, true, nil
+	case reflect.Bool:
+		return []string{strconv.FormatBool(value.Bool())}, true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(value.Int(), 10)}, true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return []string{strconv.FormatUint(value.Uint(), 10)}, true, nil
+	case reflect.Float32, reflect.Float64:
+		return []string{strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())}, true, nil
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil, false, nil
+		}
+		result := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, ok, err := encodeParam(value.Index(i), layout)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				result = append(result, item...)
+			}
+		}
+		return result, true, nil
+	default:
+		return nil, false, fmt.Errorf("unsupported parameter type: %s", value.Type())
+	}
+}
+
+func textMarshaler(value reflect.Value) (encoding.TextMarshaler, bool) {
+	if value.Type().Implements(textMarshalerType) {
+		return value.Interface().(encoding.TextMarshaler), true
+	}
+	if reflect.PtrTo(value.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		return ptr.Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+func isEmptyParam(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// bodyValue copies the body fields of the structure into the value of the new structure type, so it's encoded
+// by any codec the same way as the original structure without the lifted fields. The `XMLName` field is added
+// to keep the name of the XML element.
+func bodyValue(value reflect.Value) interface{} {
+	var prefix []reflect.StructField
+	if _, ok := value.Type().FieldByName("XMLName"); !ok && value.Type().Name() != "" {
+		prefix = append(prefix, reflect.StructField{
+			Name: "XMLName",
+			Type: xmlNameType,
+			Tag:  reflect.StructTag(`json:"-" xml:"` + value.Type().Name() + `" form:"-"`),
+		})
+	}
+	return bodyStruct(value, prefix).Interface()
+}
+
+// bodyStruct copies the exported fields of the structure, which are not lifted into the parameters, into
+// the new structure with the given prefix fields. Embedded structures are copied the same way, so they are
+// still flattened by the codecs.
+func bodyStruct(value reflect.Value, prefix []reflect.StructField) reflect.Value {
+	fields := prefix
+	values := make([]reflect.Value, len(prefix), len(prefix)+value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if kind, _, _ := paramTag(field.Tag); kind != "" && field.IsExported() {
+			continue
+		}
+		jsonName, _ := parseTag(field.Tag.Get("json"))
+		if embedded, ok := embeddedStruct(field, value.Field(i)); ok && jsonName == "" {
+			name := field.Name
+			if !field.IsExported() {
+				name = "Embedded" + name
+			}
+			embedded = bodyStruct(embedded, nil)
+			fields = append(fields, reflect.StructField{
+				Name:      name,
+				Type:      embedded.Type(),
+				Tag:       field.Tag,
+				Anonymous: true,
+			})
+			values = append(values, embedded)
+			continue
+		}
+		if !field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Ptr && value.Field(i).IsNil()) {
+			continue
+		}
+		fields = append(fields, reflect.StructField{
+			Name: field.Name,
+			Type: field.Type,
+			Tag:  field.Tag,
+		})
+		values = append(values, value.Field(i))
+	}
+	result := reflect.New(reflect.StructOf(fields)).Elem()
+	for i, field := range values {
+		if field.IsValid() {
+			result.Field(i).Set(field)
+		}
+	}
+	return result
+}
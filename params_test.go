@@ -0,0 +1,291 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testTextParam struct {
+	value string
+}
+
+func (p *testTextParam) MarshalText() ([]byte, error) {
+	return []byte("text:" + p.value), nil
+}
+
+func TestJSONClient_Request_params(t *testing.T) {
+	type Embedded struct {
+		Trace string `header:"X-Trace-Id"`
+		Note  string `json:"note"`
+	}
+	type request struct {
+		Embedded
+		Owner    string         `path:"owner"`
+		PetID    int64          `path:"petId"`
+		Status   []string       `query:"status"`
+		Tags     []string       `query:"tags,comma"`
+		Since    time.Time      `query:"since" layout:"2006-01-02"`
+		Limit    *int           `query:"limit"`
+		Offset   int            `query:"offset,omitempty"`
+		Text     testTextParam  `query:"text"`
+		Empty    string         `query:"empty"`
+		ID       string         `header:"X-Request-Id"`
+		Accept   []string       `header:"Accept"`
+		Name     string         `json:"name"`
+		Extra    map[string]int `json:"extra,omitempty"`
+		Internal string         `json:"-"`
+		Version  string         `path:"version" json:"-"`
+	}
+	limit := 10
+	body := request{
+		Embedded: Embedded{Trace: "trace", Note: "note"},
+		Owner:    "john/doe",
+		PetID:    42,
+		Status:   []string{"sold", "pending"},
+		Tags:     []string{"a", "b"},
+		Since:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Limit:    &limit,
+		Text:     testTextParam{value: "value"},
+		ID:       "123",
+		Accept:   []string{"application/json", "text/plain"},
+		Name:     "doggie",
+		Version:  "v2",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if want := "/v2/owners/john%2Fdoe/pets/42"; request.URL.EscapedPath() != want {
+			t.Errorf("wrong path: %s, want %s", request.URL.EscapedPath(), want)
+		}
+		want := map[string][]string{
+			"status": {"sold", "pending"},
+			"tags":   {"a,b"},
+			"since":  {"2024-01-02"},
+			"limit":  {"10"},
+			"text":   {"text:value"},
+			"empty":  {""},
+			"fixed":  {"yes"},
+		}
+		if got := map[string][]string(request.URL.Query()); !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong query: %v, want %v", got, want)
+		}
+		if request.Header.Get("X-Request-Id") != "123" || request.Header.Get("X-Trace-Id") != "trace" {
+			t.Errorf("wrong headers: %v", request.Header)
+		}
+		if got := request.Header.Values("Accept"); !reflect.DeepEqual(got, body.Accept) {
+			t.Errorf("wrong Accept header: %v", got)
+		}
+		data, _ := io.ReadAll(request.Body)
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Errorf("wrong body: %s", data)
+		}
+		if wantBody := map[string]interface{}{"name": "doggie", "note": "note"}; !reflect.DeepEqual(fields, wantBody) {
+			t.Errorf("wrong body: %s", data)
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewJSON(nil).POST(context.Background(), server.URL+"/{version}/owners/{owner}/pets/{petId}?fixed=yes", body, nil)
+	if err != nil {
+		t.Errorf("POST() error = %v", err)
+	}
+}
+
+func TestJSONClient_Request_paramsWithoutBody(t *testing.T) {
+	type request struct {
+		PetID string  `path:"petId"`
+		Name  *string `query:"name"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, _ := io.ReadAll(request.Body)
+		if len(data) != 0 {
+			t.Errorf("unexpected body: %s", data)
+		}
+		if request.URL.RequestURI() != "/pet/1" {
+			t.Errorf("wrong uri: %s", request.URL.RequestURI())
+		}
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewJSON(nil).GET(context.Background(), server.URL+"/pet/{petId}", &request{PetID: "1"}, nil)
+	if err != nil {
+		t.Errorf("GET() error = %v", err)
+	}
+}
+
+func TestSplitParams(t *testing.T) {
+	type untagged struct {
+		Name string `json:"name"`
+	}
+	type unsupported struct {
+		Value map[string]string `query:"value"`
+	}
+	body := untagged{Name: "name"}
+	got, params, err := splitParams(body)
+	if err != nil || params != nil || !reflect.DeepEqual(got, body) {
+		t.Errorf("splitParams() = %v, %v, %v", got, params, err)
+	}
+	got, params, err = splitParams(123)
+	if err != nil || params != nil || got != 123 {
+		t.Errorf("splitParams() = %v, %v, %v", got, params, err)
+	}
+	if _, _, err = splitParams(unsupported{Value: map[string]string{}}); err == nil {
+		t.Errorf("splitParams() error wanted")
+	}
+}
+
+func TestRequestParams_buildURL(t *testing.T) {
+	type pet struct {
+		PetID string `path:"petId"`
+	}
+	type segments struct {
+		Kind  string `path:"kind"`
+		PetID string `path:"petId"`
+	}
+	type typo struct {
+		PetID string `path:"petID"`
+	}
+	type optional struct {
+		PetID string `path:"petId,omitempty"`
+		Name  string `query:"name"`
+	}
+	tests := []struct {
+		name    string
+		body    interface{}
+		url     string
+		want    string
+		wantErr string
+	}{
+		{name: "simple", body: pet{PetID: "a/b"}, url: "/pet/{petId}", want: "/pet/a%2Fb"},
+		{name: "dot", body: pet{PetID: "."}, url: "/pet/{petId}", want: "/pet/%2E"},
+		{name: "dot dot", body: pet{PetID: ".."}, url: "/pet/{petId}", want: "/pet/%2E%2E"},
+		{name: "dot dot operator", body: pet{PetID: ".."}, url: "/pet{/petId}", want: "/pet/%2E%2E"},
+		{name: "path segments", body: segments{Kind: "pet", PetID: ".."}, url: "{/kind,petId}", want: "/pet/%2E%2E"},
+		{name: "omitted", body: optional{Name: "Rex"}, url: "/pet/{petId}", want: "/pet/?name=Rex"},
+		{
+			name:    "unused field",
+			body:    typo{PetID: "1"},
+			url:     "/pet/{petId}",
+			wantErr: `path parameter {petId} is not set`,
+		},
+		{
+			name:    "unused field with operator",
+			body:    typo{PetID: "1"},
+			url:     "/pet{/petId}",
+			wantErr: `path parameter "petID" is not used in the url`,
+		},
+		{
+			name:    "unmatched expression",
+			body:    optional{Name: "Rex"},
+			url:     "/pet/{petId}/{ownerId}",
+			wantErr: `path parameter {ownerId} is not set`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, params, err := splitParams(tt.body)
+			if err != nil {
+				t.Fatalf("splitParams() error = %v", err)
+			}
+			got, err := params.buildURL(tt.url)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("buildURL() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("buildURL() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+type testBodyInner struct {
+	Name  string `json:"name"`
+	Note  string `json:"note"`
+	Trace string `header:"X-Trace-Id"`
+}
+
+type testBodyOther struct {
+	Note string `json:"other"`
+}
+
+type testBodyHidden struct {
+	Name string `json:"name"`
+}
+
+func TestBodyValue(t *testing.T) {
+	type pet struct {
+		ID   int    `query:"id"`
+		Name string `json:"name" xml:"name"`
+	}
+	type embedded struct {
+		testBodyInner
+		*testBodyOther
+		ID   int    `path:"id"`
+		Name string `json:"title"`
+	}
+	type nilEmbedded struct {
+		*testBodyInner
+		ID int `path:"id"`
+	}
+	type unexported struct {
+		*testBodyHidden
+		ID int `path:"id"`
+	}
+	tests := []struct {
+		name    string
+		body    interface{}
+		codec   Codec
+		want    string
+		wantErr bool
+	}{
+		{name: "xml element name", body: pet{ID: 1, Name: "Rex"}, codec: XMLCodec{}, want: "<pet><name>Rex</name></pet>"},
+		{name: "json", body: pet{ID: 1, Name: "Rex"}, codec: JSONCodec{}, want: `{"name":"Rex"}` + "\n"},
+		{
+			name: "embedded",
+			body: embedded{
+				testBodyInner: testBodyInner{Name: "inner", Note: "note", Trace: "trace"},
+				testBodyOther: &testBodyOther{Note: "other"},
+				Name:          "title",
+			},
+			codec: JSONCodec{},
+			want:  `{"name":"inner","note":"note","other":"other","title":"title"}` + "\n",
+		},
+		{name: "nil embedded", body: nilEmbedded{ID: 1}, codec: JSONCodec{}, want: `{}` + "\n"},
+		{
+			name:  "unexported embedded",
+			body:  unexported{testBodyHidden: &testBodyHidden{Name: "Rex"}, ID: 1},
+			codec: JSONCodec{},
+			want:  `{"name":"Rex"}` + "\n",
+		},
+		{name: "unsupported parameter", body: struct {
+			ID chan int `path:"id"`
+		}{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _, err := splitParams(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var buffer bytes.Buffer
+			if err = tt.codec.Encode(&buffer, body); err != nil || buffer.String() != tt.want {
+				t.Errorf("Encode() = %s, error = %v, want %s", buffer.String(), err, tt.want)
+			}
+		})
+	}
+}
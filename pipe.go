@@ -0,0 +1,38 @@
+package chttp
+
+import (
+	"io"
+	"sync"
+)
+
+// pipeReader is an io.Pipe reader, that starts the writer only on the first Read call,
+// so no goroutine will be leaked if the body was closed or dropped before reading.
+type pipeReader struct {
+	write func(writer io.Writer) error
+	once  sync.Once
+	pr    *io.PipeReader
+	pw    *io.PipeWriter
+}
+
+func newPipeReader(write func(writer io.Writer) error) io.ReadCloser {
+	pr, pw := io.Pipe()
+	return &pipeReader{
+		write: write,
+		pr:    pr,
+		pw:    pw,
+	}
+}
+
+func (r *pipeReader) Read(p []byte) (int, error) {
+	r.once.Do(func() {
+		go func() {
+			_ = r.pw.CloseWithError(r.write(r.pw))
+		}()
+	})
+	return r.pr.Read(p)
+}
+
+func (r *pipeReader) Close() error {
+	r.once.Do(func() {})
+	return r.pr.Close()
+}
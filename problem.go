@@ -0,0 +1,166 @@
+package chttp
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ProblemContentType is the media type of the problem details (RFC 9457).
+const ProblemContentType = "application/problem+json"
+
+var problems = struct {
+	mu           sync.RWMutex
+	constructors map[string]func(problem *Problem) error
+}{
+	constructors: make(map[string]func(problem *Problem) error),
+}
+
+// Problem is the problem details of the HTTP API error (RFC 9457). It's decoded automatically from the
+// `application/problem+json` body of the unsuccessful response and could be received from the *Error with errors.As:
+//
+//	problem := new(chttp.Problem)
+//	if errors.As(err, &problem) {
+//		fmt.Println(problem.Title, problem.Detail)
+//	}
+type Problem struct {
+	// Type is the URI reference identifying the problem type, `about:blank` by default.
+	Type string `json:"type,omitempty"`
+	// Title is the short summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code, the response status code is used if it's not set in the body.
+	Status int `json:"status,omitempty"`
+	// Detail is the explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is the URI reference identifying this occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+	// Extensions is the list of the extension members.
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+// RegisterProblem registers the constructor of the Go error for the problem type URI. The constructed error
+// is available from the *Error with errors.Is and errors.As:
+//
+//	chttp.RegisterProblem("https://example.com/probs/out-of-credit", func(problem *chttp.Problem) error {
+//		return &OutOfCreditError{Problem: problem}
+//	})
+//
+// Registering the same type again replaces the constructor, nil constructor removes the registration.
+// The registry is global, so RegisterProblem is intended for the package initialization, use the WithProblem
+// option to register the problem type for the single client.
+func RegisterProblem(typeURI string, constructor func(problem *Problem) error) {
+	problems.mu.Lock()
+	defer problems.mu.Unlock()
+	if constructor == nil {
+		delete(problems.constructors, typeURI)
+		return
+	}
+	problems.constructors[typeURI] = constructor
+}
+
+func (p *Problem) Error() string {
+	message := p.Title
+	if message == "" {
+		message = p.Type
+	}
+	if p.Status != 0 {
+		message += " (" + strconv.Itoa(p.Status) + ")"
+	}
+	if p.Detail != "" {
+		message += ": " + p.Detail
+	}
+	return message
+}
+
+// Extension unmarshals the extension member into the value. Returns false if the member is not set.
+func (p *Problem) Extension(name string, value interface{}) (bool, error) {
+	data, ok := p.Extensions[name]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, value)
+}
+
+// UnmarshalJSON decodes the problem members and keeps all unknown members as the extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	result := problem{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	for _, name := range [...]string{"type", "title", "status", "detail", "instance"} {
+		delete(members, name)
+	}
+	if len(members) > 0 {
+		result.Extensions = members
+	}
+	if result.Type == "" {
+		result.Type = "about:blank"
+	}
+	*p = Problem(result)
+	return nil
+}
+
+// MarshalJSON encodes the problem members together with the extensions.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	members := make(map[string]json.RawMessage, len(p.Extensions)+5)
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// parseProblem decodes the problem details from the body of the unsuccessful response.
+// Returns nil if the response has another content type or the body is invalid.
+func parseProblem(config *JSONConfig, response *http.Response, body []byte) *Problem {
+	if response == nil || len(body) == 0 || isSuccess(response.StatusCode) {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if err != nil || mediaType != ProblemContentType {
+		return nil
+	}
+	problem := new(Problem)
+	if err = config.unmarshal(body, problem); err != nil {
+		return nil
+	}
+	if problem.Status == 0 {
+		problem.Status = response.StatusCode
+	}
+	return problem
+}
+
+// problemError constructs the Go error for the problem type registered with the given constructors
+// or the RegisterProblem.
+func problemError(problem *Problem, constructors map[string]func(problem *Problem) error) error {
+	if problem == nil {
+		return nil
+	}
+	if constructor, ok := constructors[problem.Type]; ok {
+		if constructor == nil {
+			return nil
+		}
+		return constructor(problem)
+	}
+	problems.mu.RLock()
+	constructor, ok := problems.constructors[problem.Type]
+	problems.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return constructor(problem)
+}
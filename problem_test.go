@@ -0,0 +1,193 @@
+package chttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type testOutOfCreditError struct {
+	Balance int
+}
+
+func (e *testOutOfCreditError) Error() string {
+	return "out of credit"
+}
+
+func testServerProblem(contentType string, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", contentType)
+		writer.WriteHeader(http.StatusForbidden)
+		_, _ = writer.Write([]byte(body))
+	}))
+}
+
+func TestJSONClient_Request_problem(t *testing.T) {
+	const body = `{
+		"type": "https://example.com/probs/out-of-credit",
+		"title": "You do not have enough credit.",
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc",
+		"balance": 30,
+		"accounts": ["/account/12345", "/account/67890"]
+	}`
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        *Problem
+	}{
+		{
+			name:        "problem",
+			contentType: "application/problem+json; charset=utf-8",
+			body:        body,
+			want: &Problem{
+				Type:     "https://example.com/probs/out-of-credit",
+				Title:    "You do not have enough credit.",
+				Status:   http.StatusForbidden,
+				Detail:   "Your current balance is 30, but that costs 50.",
+				Instance: "/account/12345/msgs/abc",
+				Extensions: map[string]json.RawMessage{
+					"balance":  json.RawMessage(`30`),
+					"accounts": json.RawMessage(`["/account/12345", "/account/67890"]`),
+				},
+			},
+		},
+		{
+			name:        "default type",
+			contentType: ProblemContentType,
+			body:        `{"status": 400, "title": "Bad Request"}`,
+			want:        &Problem{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest},
+		},
+		{name: "json", contentType: "application/json", body: body, want: nil},
+		{name: "invalid", contentType: ProblemContentType, body: `[]`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServerProblem(tt.contentType, tt.body)
+			defer server.Close()
+
+			err := NewJSON(nil).GET(context.Background(), server.URL, nil, nil)
+			cErr := new(Error)
+			if !errors.As(err, &cErr) || !cErr.IsStatusCode() {
+				t.Fatalf("GET() wrong error = %v", err)
+			}
+			if !reflect.DeepEqual(cErr.Problem, tt.want) {
+				t.Errorf("GET() wrong problem = %#v, want %#v", cErr.Problem, tt.want)
+			}
+			problem := new(Problem)
+			if errors.As(err, &problem) != (tt.want != nil) {
+				t.Errorf("errors.As() wrong result for the problem %v", tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProblem(t *testing.T) {
+	const typeURI = "https://example.com/probs/out-of-credit"
+	errOutOfCredit := errors.New("out of credit")
+	RegisterProblem(typeURI, func(problem *Problem) error {
+		result := &testOutOfCreditError{}
+		_, _ = problem.Extension("balance", &result.Balance)
+		return result
+	})
+	RegisterProblem("https://example.com/probs/sentinel", func(problem *Problem) error {
+		return errOutOfCredit
+	})
+	defer RegisterProblem(typeURI, nil)
+	defer RegisterProblem("https://example.com/probs/sentinel", nil)
+
+	server := testServerProblem(ProblemContentType, `{"type": "`+typeURI+`", "balance": 30}`)
+	defer server.Close()
+	err := NewJSON(nil).GET(context.Background(), server.URL, nil, nil)
+	target := new(testOutOfCreditError)
+	if !errors.As(err, &target) || target.Balance != 30 {
+		t.Errorf("errors.As() wrong error = %v", err)
+	}
+	problem := new(Problem)
+	if !errors.As(err, &problem) || problem.Type != typeURI {
+		t.Errorf("errors.As() wrong problem = %v", problem)
+	}
+
+	sentinel := testServerProblem(ProblemContentType, `{"type": "https://example.com/probs/sentinel"}`)
+	defer sentinel.Close()
+	err = NewJSON(nil).GET(context.Background(), sentinel.URL, nil, nil)
+	if !errors.Is(err, errOutOfCredit) || !errors.Is(err, ErrStatusCode) {
+		t.Errorf("errors.Is() wrong error = %v", err)
+	}
+}
+
+func TestWithProblem(t *testing.T) {
+	const typeURI = "https://example.com/probs/out-of-credit"
+	errGlobal := errors.New("global")
+	errClient := errors.New("client")
+	RegisterProblem(typeURI, func(problem *Problem) error {
+		return errGlobal
+	})
+	defer RegisterProblem(typeURI, nil)
+
+	server := testServerProblem(ProblemContentType, `{"type": "`+typeURI+`"}`)
+	defer server.Close()
+	tests := []struct {
+		name    string
+		options []Option
+		want    error
+	}{
+		{name: "global", want: errGlobal},
+		{name: "client", options: []Option{WithProblem(typeURI, func(problem *Problem) error { return errClient })}, want: errClient},
+		{name: "disabled", options: []Option{WithProblem(typeURI, nil)}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewJSON(nil, tt.options...)
+			for _, client := range []*JSONClient{client, client.Clone()} {
+				err := client.GET(context.Background(), server.URL, nil, nil)
+				for _, target := range []error{errGlobal, errClient} {
+					if errors.Is(err, target) != (target == tt.want) {
+						t.Errorf("errors.Is(%v) = %v, error = %v", target, !(target == tt.want), err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	problem := Problem{
+		Type:       "https://example.com/probs/out-of-credit",
+		Status:     http.StatusForbidden,
+		Extensions: map[string]json.RawMessage{"balance": json.RawMessage(`30`)},
+	}
+	data, err := json.Marshal(problem)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"balance":30,"status":403,"type":"https://example.com/probs/out-of-credit"}`
+	if string(data) != want {
+		t.Errorf("Marshal() got = %s, want %s", data, want)
+	}
+	var decoded Problem
+	if err = json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, problem) {
+		t.Errorf("Unmarshal() got = %#v, error = %v", decoded, err)
+	}
+}
+
+func TestProblem_Error(t *testing.T) {
+	tests := []struct {
+		problem Problem
+		want    string
+	}{
+		{problem: Problem{Type: "about:blank"}, want: "about:blank"},
+		{problem: Problem{Title: "Not Found", Status: 404}, want: "Not Found (404)"},
+		{problem: Problem{Title: "Forbidden", Detail: "no access"}, want: "Forbidden: no access"},
+	}
+	for _, tt := range tests {
+		if got := tt.problem.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// RequestBuilder is a fluent builder of the request, which accumulates all parts of the request
+// and sends it with the Client.
+type RequestBuilder struct {
+	client  *Client
+	method  string
+	url     string
+	params  map[string]string
+	query   neturl.Values
+	header  http.Header
+	cookies []*http.Cookie
+	// data is the request body, which is sent with each Do or Into, reader is the body stream sent once.
+	data        []byte
+	reader      io.Reader
+	contentType string
+	timeout     time.Duration
+	expect      []int
+	err         error
+}
+
+// NewRequest creates a RequestBuilder for the given method and url.
+// The url could contain path parameters in the `{name}` format, which will be replaced with the escaped values
+// set by the RequestBuilder.Param method.
+func (c *Client) NewRequest(method string, url string) *RequestBuilder {
+	return &RequestBuilder{
+		client: c,
+		method: method,
+		url:    url,
+		params: make(map[string]string),
+		query:  make(neturl.Values),
+		header: make(http.Header),
+	}
+}
+
+// Param sets the value of the path parameter. The value will be escaped as the path segment.
+func (b *RequestBuilder) Param(name string, value interface{}) *RequestBuilder {
+	b.params[name] = fmt.Sprint(value)
+	return b
+}
+
+// Query adds the values to the query parameter.
+func (b *RequestBuilder) Query(name string, values ...string) *RequestBuilder {
+	for _, value := range values {
+		b.query.Add(name, value)
+	}
+	return b
+}
+
+// Header adds the value to the request header.
+func (b *RequestBuilder) Header(name string, value string) *RequestBuilder {
+	b.header.Add(name, value)
+	return b
+}
+
+// Cookie adds the cookie to the request.
+func (b *RequestBuilder) Cookie(cookie *http.Cookie) *RequestBuilder {
+	b.cookies = append(b.cookies, cookie)
+	return b
+}
+
+// Body sets the request body, which is sent again with each Do or Into call.
+func (b *RequestBuilder) Body(body []byte) *RequestBuilder {
+	b.setBody(body, nil, "")
+	return b
+}
+
+// BodyReader sets the request body as a stream. The stream is read once, so the next Do or Into call
+// sends the remaining data.
+func (b *RequestBuilder) BodyReader(body io.Reader) *RequestBuilder {
+	b.setBody(nil, body, "")
+	return b
+}
+
+// JSON sets the request body as the marshaled value and the `Content-Type` header,
+// if it's not set with the RequestBuilder.Header method.
+func (b *RequestBuilder) JSON(body interface{}) *RequestBuilder {
+	data, err := marshal(b.client.jsonConfig, body)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.setBody(data, nil, "application/json")
+	return b
+}
+
+// Form sets the request body as the url-encoded form and the `Content-Type` header,
+// if it's not set with the RequestBuilder.Header method.
+func (b *RequestBuilder) Form(values neturl.Values) *RequestBuilder {
+	b.setBody([]byte(values.Encode()), nil, "application/x-www-form-urlencoded")
+	return b
+}
+
+// setBody replaces the previous body and its content type.
+func (b *RequestBuilder) setBody(data []byte, reader io.Reader, contentType string) {
+	b.data, b.reader, b.contentType = data, reader, contentType
+}
+
+// Timeout sets the timeout for the request, including reading the response body.
+func (b *RequestBuilder) Timeout(timeout time.Duration) *RequestBuilder {
+	b.timeout = timeout
+	return b
+}
+
+// Expect sets the list of the expected status codes. By default, the StatusPolicy of the client is used.
+func (b *RequestBuilder) Expect(statusCodes ...int) *RequestBuilder {
+	b.expect = append(b.expect, statusCodes...)
+	return b
+}
+
+// Build creates the http.Request with the given context.
+func (b *RequestBuilder) Build(ctx context.Context) (*http.Request, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	url, err := b.buildURL()
+	if err != nil {
+		return nil, err
+	}
+	body := b.reader
+	if b.data != nil {
+		body = bytes.NewReader(b.data)
+	}
+	request, err := http.NewRequestWithContext(ctx, b.method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range b.header {
+		request.Header[name] = append(request.Header[name], values...)
+	}
+	if b.contentType != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", b.contentType)
+	}
+	for _, cookie := range b.cookies {
+		request.AddCookie(cookie)
+	}
+	return request, nil
+}
+
+// Do sends the request and checks the status code of the response.
+// In case of the unexpected status code, the response body will be read and closed, and the *Error will be returned.
+func (b *RequestBuilder) Do(ctx context.Context) (*http.Response, error) {
+	response, err := b.send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	success := b.expected()
+	if success == nil {
+		success = b.client.statusPolicy(response.Request).success
+	}
+	if !success(response.StatusCode) {
+		defer func() {
+			_ = response.Body.Close()
+		}()
+		return nil, b.client.statusError(response, b.client.jsonCodecs())
+	}
+	return response, nil
+}
+
+// Into sends the request and unmarshals the response body into the given result.
+// Result should be reference type and not nil.
+func (b *RequestBuilder) Into(ctx context.Context, result interface{}) error {
+	response, err := b.send(ctx)
+	return b.client.unmarshalHTTPResponse(response, err, result, b.expected())
+}
+
+func (b *RequestBuilder) send(ctx context.Context) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if b.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+	}
+	request, err := b.Build(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response, err := b.client.Do(request)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = &cancelBody{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+func (b *RequestBuilder) buildURL() (string, error) {
+	url := b.url
+	for name, value := range b.params {
+		url = strings.ReplaceAll(url, "{"+name+"}", escapeSegment(value))
+	}
+	if start := strings.IndexByte(url, '{'); start >= 0 {
+		if end := strings.IndexByte(url[start:], '}'); end >= 0 {
+			return "", fmt.Errorf("path parameter %s is not set", url[start:start+end+1])
+		}
+	}
+	if len(b.query) == 0 {
+		return url, nil
+	}
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return "", err
+	}
+	query := uri.Query()
+	for name, values := range b.query {
+		query[name] = append(query[name], values...)
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String(), nil
+}
+
+// expected returns the success function of the expected status codes, or nil to use the StatusPolicy.
+func (b *RequestBuilder) expected() func(statusCode int) bool {
+	if len(b.expect) == 0 {
+		return nil
+	}
+	return b.isExpected
+}
+
+func (b *RequestBuilder) isExpected(statusCode int) bool {
+	return containsStatus(b.expect, statusCode)
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,207 @@
+package chttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func ExampleClient_NewRequest() {
+	var pet struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	client := NewClient(nil)
+	err := client.NewRequest(http.MethodGet, "https://petstore3.swagger.io/api/v3/pet/{petId}").
+		Param("petId", 10).
+		Header("Accept", "application/json").
+		Timeout(10*time.Second).
+		Into(context.TODO(), &pet)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(pet.Name)
+}
+
+func TestRequestBuilder_Into(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.EscapedPath() != "/pet/a%2Fb%20c" {
+			t.Errorf("wrong path: %s", request.URL.EscapedPath())
+		}
+		if values := request.URL.Query()["tags"]; len(values) != 2 || values[0] != "x" || values[1] != "y" {
+			t.Errorf("wrong query: %v", request.URL.Query())
+		}
+		if request.URL.Query().Get("status") != "sold" {
+			t.Errorf("wrong query: %v", request.URL.Query())
+		}
+		if request.Header.Get("X-Request-Id") != "123" {
+			t.Errorf("wrong header: %v", request.Header)
+		}
+		if request.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("wrong content type: %v", request.Header.Get("Content-Type"))
+		}
+		if cookie, err := request.Cookie("session"); err != nil || cookie.Value != "secret" {
+			t.Errorf("wrong cookie: %v", cookie)
+		}
+		data, _ := io.ReadAll(request.Body)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write(data)
+	}))
+	defer server.Close()
+
+	var result map[string]string
+	err := NewClient(nil).NewRequest(http.MethodPost, server.URL+"/pet/{petId}?status=sold").
+		Param("petId", "a/b c").
+		Query("tags", "x", "y").
+		Header("X-Request-Id", "123").
+		Cookie(&http.Cookie{Name: "session", Value: "secret"}).
+		JSON(map[string]string{"foo": "bar"}).
+		Expect(http.StatusCreated).
+		Into(context.Background(), &result)
+	if err != nil {
+		t.Errorf("Into() error = %v", err)
+		return
+	}
+	if result["foo"] != "bar" {
+		t.Errorf("Into() wrong result: %v", result)
+	}
+}
+
+func TestRequestBuilder_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if err := request.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		if request.PostForm.Get("name") != "doggie" {
+			t.Errorf("wrong form: %v", request.PostForm)
+		}
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte("error"))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).NewRequest(http.MethodPost, server.URL).
+		Form(url.Values{"name": {"doggie"}}).
+		Expect(http.StatusCreated).
+		Do(context.Background())
+	cErr := new(Error)
+	if !errors.As(err, &cErr) {
+		t.Errorf("Do() wrong error = %v", err)
+		return
+	}
+	if cErr.Response.StatusCode != http.StatusOK || string(cErr.Body) != "error" {
+		t.Errorf("Do() wrong error: %d %q", cErr.Response.StatusCode, cErr.Body)
+	}
+}
+
+func TestRequestBuilder_body(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		data, _ := io.ReadAll(request.Body)
+		_, _ = fmt.Fprintf(writer, "%s %s", request.Header.Get("Content-Type"), data)
+	}))
+	defer server.Close()
+
+	client := NewClient(nil)
+	tests := []struct {
+		name    string
+		builder *RequestBuilder
+		want    string
+	}{
+		{
+			name:    "json after form",
+			builder: client.NewRequest(http.MethodPost, server.URL).Form(url.Values{"a": {"1"}}).JSON("b"),
+			want:    `application/json "b"`,
+		},
+		{
+			name:    "form after json",
+			builder: client.NewRequest(http.MethodPost, server.URL).JSON("b").Form(url.Values{"a": {"1"}}),
+			want:    `application/x-www-form-urlencoded a=1`,
+		},
+		{
+			name:    "raw body after json",
+			builder: client.NewRequest(http.MethodPost, server.URL).JSON("b").Body([]byte("raw")),
+			want:    ` raw`,
+		},
+		{
+			name: "explicit content type",
+			builder: client.NewRequest(http.MethodPost, server.URL).
+				Header("Content-Type", "application/merge-patch+json").
+				JSON("b"),
+			want: `application/merge-patch+json "b"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				response, err := tt.builder.Do(context.Background())
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				data, _ := io.ReadAll(response.Body)
+				_ = response.Body.Close()
+				if string(data) != tt.want {
+					t.Errorf("Do() #%d got = %q, want %q", i, data, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestBuilder_missingParam(t *testing.T) {
+	_, err := NewClient(nil).NewRequest(http.MethodGet, "http://localhost/pet/{petId}").Do(context.Background())
+	if err == nil || err.Error() != "path parameter {petId} is not set" {
+		t.Errorf("Do() wrong error = %v", err)
+	}
+}
+
+func TestRequestBuilder_dotSegments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.EscapedPath()))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "dot", value: ".", want: "/api/pet/%2E"},
+		{name: "dot dot", value: "..", want: "/api/pet/%2E%2E"},
+		{name: "dots in value", value: "a..b", want: "/api/pet/a..b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := NewClient(nil).NewRequest(http.MethodGet, server.URL+"/api/pet/{petId}").
+				Param("petId", tt.value).
+				Do(context.Background())
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			data, _ := io.ReadAll(response.Body)
+			_ = response.Body.Close()
+			if string(data) != tt.want {
+				t.Errorf("Do() path = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestBuilder_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	_, err := NewClient(nil).NewRequest(http.MethodGet, server.URL).
+		Timeout(10 * time.Millisecond).
+		Do(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() wrong error = %v", err)
+	}
+}
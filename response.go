@@ -0,0 +1,41 @@
+package chttp
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Response is the decoded response body with the response metadata.
+type Response[T any] struct {
+	// Value is the decoded response body.
+	Value T
+	// StatusCode is the response status code.
+	StatusCode int
+	// Header is the response headers.
+	Header http.Header
+	// Trailer is the response trailers, available after the whole body is read.
+	Trailer http.Header
+	// Duration is the time spent on the request, including the redirects and decoding of the body.
+	Duration time.Duration
+	// URL is the final url of the request after the redirects.
+	URL *url.URL
+	// Response is the response with the already closed body.
+	Response *http.Response
+}
+
+// newResponse fills the metadata of the received response.
+func newResponse[T any](value T, response *http.Response, start time.Time) *Response[T] {
+	result := &Response[T]{
+		Value:      value,
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Trailer:    response.Trailer,
+		Duration:   time.Since(start),
+		Response:   response,
+	}
+	if response.Request != nil {
+		result.URL = response.Request.URL
+	}
+	return result
+}
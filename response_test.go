@@ -0,0 +1,66 @@
+package chttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGenericJSONClient_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/old":
+			http.Redirect(writer, request, "/new", http.StatusFound)
+		case "/new":
+			writer.Header().Set("ETag", `"v1"`)
+			writer.Header().Set("Trailer", "X-Checksum")
+			writer.WriteHeader(http.StatusCreated)
+			_, _ = writer.Write([]byte(`{"id": 10}`))
+			writer.Header().Set("X-Checksum", "abc")
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte(`{"code": "not_found"}`))
+		}
+	}))
+	defer server.Close()
+	type pet struct {
+		ID int `json:"id"`
+	}
+	client := NewGenericJSONClient[pet](NewJSON(nil, WithBaseURL(server.URL)))
+
+	response, err := client.Fetch(context.Background(), http.MethodGet, "/old", nil)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if response.Value.ID != 10 || response.StatusCode != http.StatusCreated {
+		t.Errorf("Fetch() got = %+v", response)
+	}
+	if response.Header.Get("ETag") != `"v1"` || response.Trailer.Get("X-Checksum") != "abc" {
+		t.Errorf("Fetch() wrong headers = %v, trailers = %v", response.Header, response.Trailer)
+	}
+	if response.URL == nil || response.URL.String() != server.URL+"/new" {
+		t.Errorf("Fetch() wrong URL = %v", response.URL)
+	}
+	if response.Duration <= 0 {
+		t.Errorf("Fetch() wrong Duration = %v", response.Duration)
+	}
+
+	response, err = client.Fetch(context.Background(), http.MethodGet, "/missing", nil)
+	if !errors.Is(err, ErrStatusCode) || response == nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("Fetch() got = %+v, error = %v", response, err)
+	}
+
+	errorClient := NewGenericJSONErrorClient[pet, testAPIError](NewJSON(nil, WithBaseURL(server.URL)))
+	response, err = errorClient.Fetch(context.Background(), http.MethodGet, "/missing", nil)
+	apiErr := new(ResponseError[testAPIError])
+	if !errors.As(err, &apiErr) || apiErr.Value.Code != "not_found" || response.StatusCode != http.StatusNotFound {
+		t.Errorf("Fetch() got = %+v, error = %v", response, err)
+	}
+
+	response, err = client.Fetch(context.Background(), http.MethodGet, "http://[::1", nil)
+	if err == nil || response != nil {
+		t.Errorf("Fetch() got = %+v, error = %v", response, err)
+	}
+}
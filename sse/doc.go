@@ -0,0 +1,2 @@
+// Package sse provides the Server-Sent Events client on top of the cHTTP clients.
+package sse
@@ -0,0 +1,143 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is a message received from the `text/event-stream`.
+type Event struct {
+	// ID is the last event ID of the stream at the moment of the event dispatch.
+	ID string
+	// Event is the event type, `message` by default.
+	Event string
+	// Data is the event data, multiple `data` lines are joined with the line feed.
+	Data string
+}
+
+// decoder parses the `text/event-stream` format (https://html.spec.whatwg.org/multipage/server-sent-events.html).
+type decoder struct {
+	scanner     *bufio.Scanner
+	lastEventID string
+	idBuffer    string
+	retry       time.Duration
+	first       bool
+}
+
+func newDecoder(reader io.Reader, maxLineSize int) *decoder {
+	size := 4096
+	if maxLineSize < size {
+		size = maxLineSize
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, size), maxLineSize)
+	scanner.Split(scanLines)
+	return &decoder{
+		scanner: scanner,
+		first:   true,
+	}
+}
+
+// Next reads the stream until the next event is dispatched. Comments and events without data are skipped.
+// Returns io.EOF if the stream is finished, the incomplete event is discarded in this case.
+func (d *decoder) Next() (Event, error) {
+	var (
+		event Event
+		data  strings.Builder
+		empty = true
+	)
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+		if d.first {
+			d.first = false
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" {
+			// the last event ID is updated only when the event is dispatched
+			d.lastEventID = d.idBuffer
+			if empty {
+				event = Event{}
+				continue
+			}
+			event.ID = d.lastEventID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			return event, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value := line, ""
+		if index := strings.IndexByte(line, ':'); index >= 0 {
+			field, value = line[:index], strings.TrimPrefix(line[index+1:], " ")
+		}
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			empty = false
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.idBuffer = value
+			}
+		case "retry":
+			if isDigits(value) {
+				if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+					d.retry = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+	}
+	if err := d.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+	return Event{}, io.EOF
+}
+
+// scanLines is a bufio.SplitFunc for the lines terminated with the `\r\n`, `\n`, or `\r`.
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if index := bytes.IndexAny(data, "\r\n"); index >= 0 {
+		if data[index] == '\n' {
+			return index + 1, data[:index], nil
+		}
+		if index+1 < len(data) {
+			if data[index+1] == '\n' {
+				return index + 2, data[:index], nil
+			}
+			return index + 1, data[:index], nil
+		}
+		if atEOF {
+			return index + 1, data[:index], nil
+		}
+		// request more data to check the `\r\n` sequence
+		return 0, nil, nil
+	}
+	if atEOF {
+		// incomplete line at the end of the stream is discarded with the incomplete event
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
+
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package sse
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecoder_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+		lastID string
+		retry  time.Duration
+	}{
+		{
+			name:   "simple",
+			stream: "data: hello\n\n",
+			want:   []Event{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "multi-line data",
+			stream: "data: first\ndata:second\ndata\n\n",
+			want:   []Event{{Event: "message", Data: "first\nsecond\n"}},
+		},
+		{
+			name:   "fields",
+			stream: "event: update\nid: 42\nretry: 1500\ndata: {\"a\":1}\n\n",
+			want:   []Event{{ID: "42", Event: "update", Data: `{"a":1}`}},
+			lastID: "42",
+			retry:  1500 * time.Millisecond,
+		},
+		{
+			name:   "comments and unknown fields",
+			stream: ": ping\nfoo: bar\ndata: x\n\n:\n\n",
+			want:   []Event{{Event: "message", Data: "x"}},
+		},
+		{
+			name:   "id persists",
+			stream: "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			want: []Event{
+				{ID: "1", Event: "message", Data: "a"},
+				{ID: "1", Event: "message", Data: "b"},
+				{ID: "", Event: "message", Data: "c"},
+			},
+		},
+		{
+			name:   "event without data is skipped",
+			stream: "event: empty\n\ndata: a\n\n",
+			want:   []Event{{Event: "message", Data: "a"}},
+		},
+		{
+			name:   "line endings",
+			stream: "data: a\r\ndata: b\rdata: c\n\r\ndata: d\r\r",
+			want:   []Event{{Event: "message", Data: "a\nb\nc"}, {Event: "message", Data: "d"}},
+		},
+		{
+			name:   "byte order mark",
+			stream: "\ufeffdata: a\n\n",
+			want:   []Event{{Event: "message", Data: "a"}},
+		},
+		{
+			name:   "invalid retry",
+			stream: "retry: 1s\nretry: -1\ndata: a\n\n",
+			want:   []Event{{Event: "message", Data: "a"}},
+		},
+		{
+			name:   "incomplete event is discarded",
+			stream: "data: a\n\ndata: b\n",
+			want:   []Event{{Event: "message", Data: "a"}},
+		},
+		{
+			name:   "id of incomplete event is not dispatched",
+			stream: "id: 1\ndata: a\n\nid: 2\ndata: b\n",
+			want:   []Event{{ID: "1", Event: "message", Data: "a"}},
+			lastID: "1",
+		},
+		{
+			name:   "id with null",
+			stream: "id: 1\x002\ndata: a\n\n",
+			want:   []Event{{Event: "message", Data: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newDecoder(strings.NewReader(tt.stream), DefaultMaxLineSize)
+			var got []Event
+			for {
+				event, err := decoder.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next() error = %v", err)
+				}
+				got = append(got, event)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next() got = %#v, want %#v", got, tt.want)
+			}
+			if tt.lastID != "" && decoder.lastEventID != tt.lastID {
+				t.Errorf("lastEventID = %q, want %q", decoder.lastEventID, tt.lastID)
+			}
+			if decoder.retry != tt.retry {
+				t.Errorf("retry = %v, want %v", decoder.retry, tt.retry)
+			}
+		})
+	}
+}
+
+func TestDecoder_Next_maxLineSize(t *testing.T) {
+	decoder := newDecoder(strings.NewReader("data: "+strings.Repeat("a", 100)+"\n\n"), 10)
+	if _, err := decoder.Next(); err == nil || err == io.EOF {
+		t.Errorf("Next() error = %v", err)
+	}
+}
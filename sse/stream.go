@@ -0,0 +1,272 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+const (
+	// DefaultRetry is the default reconnection delay, if it was not set by the server or in the Config.
+	DefaultRetry = 3 * time.Second
+	// DefaultMaxLineSize is the default maximal size of the line in the stream.
+	DefaultMaxLineSize = 1 << 20
+)
+
+// ErrContentType is returned if the server responds with the content type other than `text/event-stream`.
+var ErrContentType = errors.New("sse: unexpected content type")
+
+// Config is a configuration of the Stream.
+type Config struct {
+	// Method is the HTTP method of the request, http.MethodGet by default.
+	Method string
+	// Body is the request body, which is sent with every connection.
+	Body []byte
+	// Header is a list of the additional request headers.
+	Header http.Header
+	// LastEventID is the initial value of the `Last-Event-ID` header.
+	LastEventID string
+	// Retry is the initial reconnection delay, DefaultRetry by default. The server could change it with the
+	// `retry` field.
+	Retry time.Duration
+	// MaxReconnects is the maximal number of consecutive reconnects without receiving an event.
+	// Zero means unlimited, negative value disables reconnects: the stream finishes when the server closes
+	// the connection.
+	MaxReconnects int
+	// MaxLineSize is the maximal size of the line in the stream, DefaultMaxLineSize by default.
+	MaxLineSize int
+}
+
+// Stream is the Server-Sent Events stream. It connects to the server with the chttp.Client, so every connection
+// and reconnect goes through the client middlewares. When the connection is lost, the Stream waits for the retry
+// interval and reconnects with the `Last-Event-ID` header.
+//
+// The connection is not restored if the server responds with the status code other than `200 OK` or with the content
+// type other than `text/event-stream`, in this case Stream.Err returns the error. `204 No Content` status code
+// finishes the stream without an error.
+type Stream struct {
+	client   *chttp.Client
+	url      string
+	config   Config
+	parent   context.Context
+	ctx      context.Context
+	cancel   context.CancelFunc
+	response *http.Response
+	decoder  *decoder
+	event    Event
+	retry    time.Duration
+	failures int
+	err      error
+	done     bool
+}
+
+// Connect creates the Stream for the given url. The connection is established lazily with the first
+// Stream.Next call. The Stream should be closed to release the connection.
+func Connect(ctx context.Context, client *chttp.Client, url string, config Config) *Stream {
+	if client == nil {
+		client = chttp.NewClient(nil)
+	}
+	if config.Method == "" {
+		config.Method = http.MethodGet
+	}
+	if config.Retry <= 0 {
+		config.Retry = DefaultRetry
+	}
+	if config.MaxLineSize <= 0 {
+		config.MaxLineSize = DefaultMaxLineSize
+	}
+	child, cancel := context.WithCancel(ctx)
+	return &Stream{
+		parent: ctx,
+		ctx:    child,
+		client: client,
+		url:    url,
+		config: config,
+		cancel: cancel,
+		retry:  config.Retry,
+	}
+}
+
+// Next waits for the next event, which is available with the Stream.Event method.
+// Returns false if the stream is finished, closed, or failed, check the Stream.Err after that.
+func (s *Stream) Next() bool {
+	for s.err == nil && !s.done {
+		if s.decoder == nil {
+			if err := s.connect(); err != nil {
+				s.reconnect(err)
+				continue
+			}
+			if s.done || s.err != nil {
+				break
+			}
+		}
+		event, err := s.decoder.Next()
+		if s.decoder.retry > 0 {
+			s.retry = s.decoder.retry
+		}
+		s.config.LastEventID = s.decoder.lastEventID
+		if err == nil {
+			s.event = event
+			s.failures = 0
+			return true
+		}
+		s.disconnect()
+		s.reconnect(err)
+	}
+	s.disconnect()
+	return false
+}
+
+// Event returns the last event received by the Stream.Next method.
+func (s *Stream) Event() Event {
+	return s.event
+}
+
+// LastEventID returns the last event ID received from the server, it will be sent with the next reconnect.
+func (s *Stream) LastEventID() string {
+	return s.config.LastEventID
+}
+
+// Err returns the error, which finished the stream.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// Close closes the connection and stops the stream. It's safe to call it concurrently with the Stream.Next.
+func (s *Stream) Close() error {
+	s.cancel()
+	return nil
+}
+
+// Events returns the channel with the events, the channel is closed when the stream is finished.
+// Check the Stream.Err after the channel is closed. The Stream should not be used directly after this call.
+func (s *Stream) Events() <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for s.Next() {
+			select {
+			case events <- s.event:
+			case <-s.ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// All returns the iterator over the events, which could be used with the range-over-func loop.
+// The error is yielded once as the last element. The stream is closed after the iteration.
+func (s *Stream) All() func(yield func(Event, error) bool) {
+	return func(yield func(Event, error) bool) {
+		defer func() {
+			_ = s.Close()
+		}()
+		for s.Next() {
+			if !yield(s.event, nil) {
+				return
+			}
+		}
+		if s.err != nil {
+			yield(Event{}, s.err)
+		}
+	}
+}
+
+// connect sends the request. Returns the error, if the connection should be restored.
+func (s *Stream) connect() error {
+	request, err := http.NewRequestWithContext(s.ctx, s.config.Method, s.url, bytes.NewReader(s.config.Body))
+	if err != nil {
+		s.err = err
+		return nil
+	}
+	for name, values := range s.config.Header {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Cache-Control", "no-cache")
+	if s.config.LastEventID != "" {
+		request.Header.Set("Last-Event-ID", s.config.LastEventID)
+	}
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	switch {
+	case response.StatusCode == http.StatusNoContent:
+		_ = response.Body.Close()
+		s.done = true
+	case response.StatusCode != http.StatusOK:
+		defer func() {
+			_ = response.Body.Close()
+		}()
+		data, err := io.ReadAll(io.LimitReader(response.Body, int64(s.config.MaxLineSize)))
+		s.err = chttp.NewError(response, data, err)
+	case !isEventStream(response.Header.Get("Content-Type")):
+		_ = response.Body.Close()
+		s.err = fmt.Errorf("%w: %q", ErrContentType, response.Header.Get("Content-Type"))
+	default:
+		s.response = response
+		s.decoder = newDecoder(response.Body, s.config.MaxLineSize)
+		s.decoder.lastEventID = s.config.LastEventID
+		s.decoder.idBuffer = s.config.LastEventID
+	}
+	return nil
+}
+
+func (s *Stream) disconnect() {
+	if s.response != nil {
+		_ = s.response.Body.Close()
+		s.response = nil
+	}
+	s.decoder = nil
+}
+
+// reconnect waits for the retry interval before the next connection, or finishes the stream.
+func (s *Stream) reconnect(cause error) {
+	if s.done {
+		return
+	}
+	if s.ctx.Err() != nil {
+		s.stop()
+		return
+	}
+	if s.config.MaxReconnects < 0 && cause == io.EOF {
+		s.done = true
+		return
+	}
+	s.failures++
+	if s.config.MaxReconnects < 0 || (s.config.MaxReconnects > 0 && s.failures > s.config.MaxReconnects) {
+		s.err = fmt.Errorf("sse: connection lost: %w", cause)
+		return
+	}
+	timer := time.NewTimer(s.retry)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-s.ctx.Done():
+		s.stop()
+	}
+}
+
+// stop finishes the stream with the context error, if the parent context is done, or without an error,
+// if the stream was closed.
+func (s *Stream) stop() {
+	if err := s.parent.Err(); err != nil {
+		s.err = err
+	} else {
+		s.done = true
+	}
+}
+
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
@@ -0,0 +1,207 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+// testServer sends two events per connection with the increasing IDs and closes the connection
+// in the middle of the third event.
+// It records the `Last-Event-ID` header of every connection.
+func testServer(t *testing.T, connections int) (*httptest.Server, func() []string) {
+	var (
+		mu     sync.Mutex
+		lastID []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		lastID = append(lastID, request.Header.Get("Last-Event-ID"))
+		count := len(lastID)
+		mu.Unlock()
+		if request.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("wrong Accept header: %q", request.Header.Get("Accept"))
+		}
+		if count > connections {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/event-stream")
+		writer.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(writer, "retry: 10\n\n: comment\n\n")
+		for i := 1; i <= 2; i++ {
+			_, _ = fmt.Fprintf(writer, "id: %d\nevent: tick\ndata: %d\n\n", 2*(count-1)+i, i)
+			writer.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprintf(writer, "id: %d\ndata: incomplete", 2*count+1)
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lastID...)
+	}
+}
+
+func TestStream_reconnect(t *testing.T) {
+	server, lastIDs := testServer(t, 2)
+	defer server.Close()
+
+	var calls int
+	client := chttp.NewClient(nil, chttp.WithMiddleware(
+		func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+			calls++
+			request.Header.Set("Authorization", "Bearer token")
+			return next(request)
+		},
+	))
+	stream := Connect(context.Background(), client, server.URL, Config{Retry: time.Hour, LastEventID: "0"})
+	defer func() {
+		_ = stream.Close()
+	}()
+	var got []Event
+	for stream.Next() {
+		got = append(got, stream.Event())
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("Err() error = %v", err)
+	}
+	want := []Event{
+		{ID: "1", Event: "tick", Data: "1"},
+		{ID: "2", Event: "tick", Data: "2"},
+		{ID: "3", Event: "tick", Data: "1"},
+		{ID: "4", Event: "tick", Data: "2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Next() got = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(lastIDs(), []string{"0", "2", "4"}) {
+		t.Errorf("wrong Last-Event-ID headers: %v", lastIDs())
+	}
+	if calls != 3 {
+		t.Errorf("middleware calls = %d, want 3", calls)
+	}
+	if stream.LastEventID() != "4" {
+		t.Errorf("LastEventID() = %q", stream.LastEventID())
+	}
+}
+
+func TestStream_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		config  Config
+		check   func(err error) bool
+	}{
+		{
+			name: "status code",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusUnauthorized)
+				_, _ = writer.Write([]byte("unauthorized"))
+			},
+			check: func(err error) bool {
+				cErr := new(chttp.Error)
+				return errors.As(err, &cErr) && cErr.IsStatusCode() && string(cErr.Body) == "unauthorized" &&
+					strings.HasPrefix(err.Error(), "GET http://")
+			},
+		},
+		{
+			name: "content type",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Type", "application/json")
+				_, _ = writer.Write([]byte("{}"))
+			},
+			check: func(err error) bool {
+				return errors.Is(err, ErrContentType)
+			},
+		},
+		{
+			name: "max reconnects",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				hijacker := writer.(http.Hijacker)
+				conn, _, _ := hijacker.Hijack()
+				_ = conn.Close()
+			},
+			config: Config{Retry: time.Millisecond, MaxReconnects: 2},
+			check: func(err error) bool {
+				return err != nil
+			},
+		},
+		{
+			name: "no reconnect",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Type", "text/event-stream")
+				_, _ = writer.Write([]byte("data: a\n\n"))
+			},
+			config: Config{MaxReconnects: -1},
+			check: func(err error) bool {
+				return err == nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			stream := Connect(context.Background(), nil, server.URL, tt.config)
+			defer func() {
+				_ = stream.Close()
+			}()
+			for stream.Next() {
+				_ = stream.Event()
+			}
+			if !tt.check(stream.Err()) {
+				t.Errorf("Err() wrong error = %v", stream.Err())
+			}
+		})
+	}
+}
+
+func TestStream_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/event-stream")
+		_, _ = writer.Write([]byte("data: a\n\n"))
+		writer.(http.Flusher).Flush()
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	stream := Connect(context.Background(), nil, server.URL, Config{})
+	events := stream.Events()
+	if event := <-events; event.Data != "a" {
+		t.Errorf("Events() wrong event = %v", event)
+	}
+	_ = stream.Close()
+	for event := range events {
+		t.Errorf("Events() unexpected event = %v", event)
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("Err() error = %v", err)
+	}
+}
+
+func TestStream_context(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stream := Connect(ctx, nil, server.URL, Config{Retry: 10 * time.Millisecond})
+	stream.All()(func(event Event, err error) bool {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("All() wrong error = %v", err)
+		}
+		return true
+	})
+}
@@ -0,0 +1,122 @@
+package chttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// StatusPolicy describes which responses are successful and how their bodies are decoded by the JSONClient,
+// FormClient, CodecClient, generic clients, and the RequestBuilder.Into method. The policy could be set
+// for the client with the WithStatusPolicy option, and for the single call with the ContextWithStatusPolicy.
+//
+//	ctx = chttp.ContextWithStatusPolicy(ctx, chttp.StatusPolicy{
+//		Empty:   []int{http.StatusNotFound},
+//		Targets: map[int]interface{}{http.StatusConflict: &conflict},
+//	})
+//	pet, err := client.GET(ctx, "/pet/10", nil) // zero value and nil error for 404
+//	var target *chttp.TargetError
+//	if errors.As(err, &target) {
+//		log.Println("conflict:", conflict.Reason) // 409 is decoded into the conflict
+//	}
+type StatusPolicy struct {
+	// Success reports whether the body of the response with the status code should be decoded into the result.
+	// The *Error is returned for other status codes. All status codes below 300 are successful by default.
+	// Streams use only this field of the policy.
+	Success func(statusCode int) bool
+	// Empty is the list of the status codes, which result in the zero value and nil error.
+	Empty []int
+	// Targets are the decode targets of the status codes. The body of the response with the status code
+	// is decoded into the target instead of the result, and the *TargetError is returned. Nil target discards
+	// the body. Targets are used only in the ContextWithStatusPolicy, the targets of the client policy are never
+	// used, as they would be shared by the concurrent calls.
+	Targets map[int]interface{}
+	// Check validates the body of the successful response before decoding, e.g. rejects `200 OK` responses
+	// with the `{"error": ...}` body. The error is returned as the base error of the *Error with the body.
+	// The body is read into the memory if the Check is set.
+	Check func(response *http.Response, body []byte) error
+}
+
+// TargetError is returned, if the response body was decoded into the StatusPolicy.Targets target
+// instead of the result.
+type TargetError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Target is the filled target.
+	Target interface{}
+}
+
+func (e *TargetError) Error() string {
+	return fmt.Sprintf("response with the %d status code is decoded into the target", e.StatusCode)
+}
+
+type statusPolicyKey struct{}
+
+// ContextWithStatusPolicy returns the context with the StatusPolicy of the single call. The fields, which are set,
+// override the fields of the client policy. The Targets are not merged, only the targets of the single call
+// are used.
+func ContextWithStatusPolicy(ctx context.Context, policy StatusPolicy) context.Context {
+	return context.WithValue(ctx, statusPolicyKey{}, policy)
+}
+
+// AcceptStatus returns the StatusPolicy.Success function, which accepts only the given status codes.
+func AcceptStatus(statusCodes ...int) func(statusCode int) bool {
+	return func(statusCode int) bool {
+		return containsStatus(statusCodes, statusCode)
+	}
+}
+
+// statusPolicy merges the client policy with the policy of the request context. The Targets are taken only
+// from the policy of the request context.
+func (c *Client) statusPolicy(request *http.Request) StatusPolicy {
+	var policy StatusPolicy
+	if c.policy != nil {
+		policy = *c.policy
+		policy.Targets = nil
+	}
+	if request == nil {
+		return policy
+	}
+	override, ok := request.Context().Value(statusPolicyKey{}).(StatusPolicy)
+	if !ok {
+		return policy
+	}
+	if override.Success != nil {
+		policy.Success = override.Success
+	}
+	if override.Empty != nil {
+		policy.Empty = override.Empty
+	}
+	if override.Targets != nil {
+		policy.Targets = override.Targets
+	}
+	if override.Check != nil {
+		policy.Check = override.Check
+	}
+	return policy
+}
+
+func (p StatusPolicy) success(statusCode int) bool {
+	if p.Success == nil {
+		return isSuccess(statusCode)
+	}
+	return p.Success(statusCode)
+}
+
+func containsStatus(statusCodes []int, statusCode int) bool {
+	for _, code := range statusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// resetResult sets the value referenced by the result to the zero value.
+func resetResult(result interface{}) {
+	value := reflect.ValueOf(unwrapResult(&result))
+	if value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().CanSet() {
+		value.Elem().Set(reflect.Zero(value.Elem().Type()))
+	}
+}
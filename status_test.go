@@ -0,0 +1,160 @@
+package chttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStatusPolicy(t *testing.T) {
+	// the server responds with the status code from the path and the body from the `body` query parameter.
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/"))
+		writer.WriteHeader(code)
+		_, _ = writer.Write([]byte(request.URL.Query().Get("body")))
+	}))
+	defer server.Close()
+	type pet struct {
+		ID    int    `json:"id"`
+		Error string `json:"error"`
+	}
+	type conflict struct {
+		Reason string `json:"reason"`
+	}
+	errAPI := errors.New("api error")
+	checkError := func(response *http.Response, body []byte) error {
+		if bytes.Contains(body, []byte(`"error"`)) {
+			return errAPI
+		}
+		return nil
+	}
+
+	var target conflict
+	tests := []struct {
+		name    string
+		client  StatusPolicy
+		call    *StatusPolicy
+		path    string
+		want    pet
+		wantErr error
+	}{
+		{name: "default", path: `/200?body={"id":1}`, want: pet{ID: 1}},
+		{name: "default error", path: `/404?body={"id":1}`, wantErr: ErrStatusCode},
+		{name: "empty", client: StatusPolicy{Empty: []int{404}}, path: `/404?body={"id":1}`, want: pet{}},
+		{
+			name:   "empty per call",
+			client: StatusPolicy{Empty: []int{410}},
+			call:   &StatusPolicy{Empty: []int{404}},
+			path:   `/404?body={"id":1}`,
+			want:   pet{},
+		},
+		{
+			name:    "per call override",
+			client:  StatusPolicy{Empty: []int{404}},
+			call:    &StatusPolicy{Empty: []int{410}},
+			path:    `/404`,
+			wantErr: ErrStatusCode,
+		},
+		{
+			name:    "client targets are ignored",
+			client:  StatusPolicy{Targets: map[int]interface{}{409: &target}},
+			path:    `/409?body={"reason":"exists"}`,
+			wantErr: ErrStatusCode,
+		},
+		{
+			name:    "success",
+			client:  StatusPolicy{Success: AcceptStatus(http.StatusOK)},
+			path:    `/201?body={"id":1}`,
+			wantErr: ErrStatusCode,
+		},
+		{
+			name:   "accepted",
+			client: StatusPolicy{Success: AcceptStatus(http.StatusOK, http.StatusAccepted)},
+			path:   `/202?body={"id":2}`,
+			want:   pet{ID: 2},
+		},
+		{
+			name:    "check",
+			client:  StatusPolicy{Check: checkError},
+			path:    `/200?body={"error":"failed"}`,
+			want:    pet{ID: 5},
+			wantErr: errAPI,
+		},
+		{name: "check passed", client: StatusPolicy{Check: checkError}, path: `/200?body={"id":3}`, want: pet{ID: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target = conflict{}
+			client := NewJSON(nil, WithBaseURL(server.URL), WithStatusPolicy(tt.client))
+			ctx := context.Background()
+			if tt.call != nil {
+				ctx = ContextWithStatusPolicy(ctx, *tt.call)
+			}
+			got := pet{ID: 5}
+			err := client.GET(ctx, tt.path, nil, &got)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("GET() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("GET() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	client := NewGenericJSONClient[pet](NewJSON(nil, WithBaseURL(server.URL)))
+	ctx := ContextWithStatusPolicy(context.Background(), StatusPolicy{Targets: map[int]interface{}{409: &target}})
+	_, err := client.GET(ctx, `/409?body={"reason":"exists"}`, nil)
+	targetErr := new(TargetError)
+	if !errors.As(err, &targetErr) || targetErr.StatusCode != 409 || targetErr.Target != &target || target.Reason != "exists" {
+		t.Errorf("GET() target = %+v, error = %v", target, err)
+	}
+	_, err = client.GET(ctx, `/409?body=invalid`, nil)
+	if err == nil || !strings.Contains(err.Error(), "unmarshaling response error") {
+		t.Errorf("GET() wrong error = %v", err)
+	}
+
+	// the per-call targets replace the client targets, which are never used.
+	var clientTarget conflict
+	target = conflict{}
+	client = NewGenericJSONClient[pet](NewJSON(nil, WithBaseURL(server.URL), WithStatusPolicy(StatusPolicy{
+		Targets: map[int]interface{}{409: &clientTarget},
+	})))
+	ctx = ContextWithStatusPolicy(context.Background(), StatusPolicy{Targets: map[int]interface{}{410: &target}})
+	_, err = client.GET(ctx, `/409?body={"reason":"exists"}`, nil)
+	if !errors.Is(err, ErrStatusCode) || clientTarget.Reason != "" {
+		t.Errorf("GET() client target = %+v, error = %v", clientTarget, err)
+	}
+	_, err = client.GET(ctx, `/410?body={"reason":"gone"}`, nil)
+	if !errors.As(err, &targetErr) || targetErr.StatusCode != 410 || target.Reason != "gone" {
+		t.Errorf("GET() target = %+v, error = %v", target, err)
+	}
+
+	builder := NewClient(nil, WithStatusPolicy(StatusPolicy{Success: AcceptStatus(http.StatusNotFound)}))
+	response, err := builder.NewRequest(http.MethodGet, server.URL+"/404").Do(context.Background())
+	if err != nil {
+		t.Errorf("Do() error = %v", err)
+	} else {
+		_ = response.Body.Close()
+	}
+	err = builder.NewRequest(http.MethodGet, server.URL+"/200").Expect(http.StatusOK).Into(context.Background(), nil)
+	if err != nil {
+		t.Errorf("Into() error = %v", err)
+	}
+
+	stream := StreamJSON[pet](ContextWithStatusPolicy(context.Background(), StatusPolicy{
+		Success: AcceptStatus(http.StatusNotFound),
+	}), NewJSON(nil), http.MethodGet, server.URL+`/404?body={"id":4}`, nil)
+	for stream.Next() {
+		if stream.Value().ID != 4 {
+			t.Errorf("Stream() got = %v", stream.Value())
+		}
+	}
+	if stream.Err() != nil {
+		t.Errorf("Stream() error = %v", stream.Err())
+	}
+}
@@ -0,0 +1,223 @@
+package chttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Stream is an iterator over the JSON records of the response body, which decodes them one by one
+// without loading the whole body in memory. Supported formats are the newline-delimited JSON
+// (`application/x-ndjson`, JSON Lines) and the top-level JSON array, whose elements are returned as the records.
+//
+//	stream := chttp.StreamJSON[Row](ctx, client, http.MethodGet, "/export", nil)
+//	defer stream.Close()
+//	for stream.Next() {
+//		row := stream.Value()
+//	}
+//	if err := stream.Err(); err != nil {
+//		return err
+//	}
+type Stream[T any] struct {
+	response *http.Response
+	decoder  *json.Decoder
+	config   *JSONConfig
+	array    bool
+	started  bool
+	value    T
+	err      error
+	done     bool
+}
+
+// StreamJSON sends the request with the JSONClient and returns the Stream over the records of the response body.
+// The request body is prepared the same way as in the JSONClient.Request. The response body size is limited
+// with the WithMaxResponseSize option. Stream should be closed, if it was not read until the end.
+func StreamJSON[T any](
+	ctx context.Context,
+	client *JSONClient,
+	method string,
+	url string,
+	body interface{},
+) *Stream[T] {
+	body, params, err := splitParams(body)
+	if err != nil {
+		return &Stream[T]{err: err}
+	}
+	data, err := marshal(client.Client.jsonConfig, body)
+	if err != nil {
+		return &Stream[T]{err: err}
+	}
+	response, err := client.Client.request(ctx, method, url, bytes.NewBuffer(data), params)
+	return newStream[T](client.Client, response, err)
+}
+
+func newStream[T any](client *Client, response *http.Response, httpErr error) *Stream[T] {
+	if httpErr != nil {
+		return &Stream[T]{err: NewError(response, nil, fmt.Errorf("requesting error: %w", httpErr))}
+	}
+	stream := &Stream[T]{response: response, config: client.jsonConfig}
+	if !client.statusPolicy(response.Request).success(response.StatusCode) {
+		defer func() {
+			_ = stream.Close()
+		}()
+		stream.err = client.statusError(response, client.jsonCodecs())
+		return stream
+	}
+	reader := bufio.NewReader(limitBody(response.Body, client.maxResponseSize))
+	stream.array = !isJSONLines(response.Header.Get("Content-Type")) && firstByte(reader) == '['
+	stream.decoder = client.jsonConfig.newDecoder(reader)
+	return stream
+}
+
+// Next decodes the next record, which is available with the Stream.Value method.
+// Returns false at the end of the stream or in case of an error, check the Stream.Err after that.
+func (s *Stream[T]) Next() bool {
+	if s.err != nil || s.done {
+		return false
+	}
+	var value T
+	if err := s.decode(&value); err != nil {
+		if err == io.EOF {
+			s.done = true
+		} else {
+			s.err = s.wrap(err)
+		}
+		_ = s.Close()
+		return false
+	}
+	s.value = value
+	return true
+}
+
+// Value returns the last record decoded by the Stream.Next method.
+func (s *Stream[T]) Value() T {
+	return s.value
+}
+
+// Err returns the first error occurred during the request or decoding.
+func (s *Stream[T]) Err() error {
+	return s.err
+}
+
+// Close closes the response body. It's safe to call it multiple times.
+func (s *Stream[T]) Close() error {
+	if s.response == nil || s.response.Body == nil {
+		return nil
+	}
+	body := s.response.Body
+	s.response.Body = nil
+	return body.Close()
+}
+
+// Response returns the http.Response of the stream, the body of it should not be read directly.
+func (s *Stream[T]) Response() *http.Response {
+	return s.response
+}
+
+// All returns the iterator over the records, which could be used with the range-over-func loop.
+// The error is yielded once as the last element. The stream is closed after the iteration.
+//
+//	for row, err := range stream.All() {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func (s *Stream[T]) All() func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		defer func() {
+			_ = s.Close()
+		}()
+		for s.Next() {
+			if !yield(s.value, nil) {
+				return
+			}
+		}
+		if s.err != nil {
+			var zero T
+			yield(zero, s.err)
+		}
+	}
+}
+
+func (s *Stream[T]) decode(value *T) error {
+	if !s.array {
+		return s.decodeValue(value)
+	}
+	if !s.started {
+		s.started = true
+		if _, err := s.decoder.Token(); err != nil {
+			return err
+		}
+	}
+	if s.decoder.More() {
+		return s.decodeValue(value)
+	}
+	if _, err := s.decoder.Token(); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if _, err := s.decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("unexpected data after top-level value")
+		}
+		return err
+	}
+	return io.EOF
+}
+
+// decodeValue decodes the record with the custom JSONConfig.Unmarshal function, if it's set.
+func (s *Stream[T]) decodeValue(value *T) error {
+	if s.config == nil || s.config.Unmarshal == nil {
+		return s.decoder.Decode(value)
+	}
+	var data json.RawMessage
+	if err := s.decoder.Decode(&data); err != nil {
+		return err
+	}
+	return s.config.Unmarshal(data, value)
+}
+
+func (s *Stream[T]) wrap(err error) error {
+	if errors.Is(err, ErrResponseTooLarge) {
+		return NewError(s.response, nil, fmt.Errorf("reading response body error: %w", err))
+	}
+	return NewError(s.response, nil, fmt.Errorf("unmarshaling response error: %w", err))
+}
+
+func isJSONLines(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines",
+		"application/jsonlines", "application/json-lines":
+		return true
+	default:
+		return false
+	}
+}
+
+// firstByte returns the first non-whitespace byte of the reader without consuming it.
+func firstByte(reader *bufio.Reader) byte {
+	for i := 1; ; i++ {
+		data, err := reader.Peek(i)
+		if len(data) < i || err != nil {
+			return 0
+		}
+		switch c := data[i-1]; c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		default:
+			return c
+		}
+	}
+}
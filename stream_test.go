@@ -0,0 +1,217 @@
+package chttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testServerStream(status int, contentType string, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", contentType)
+		writer.WriteHeader(status)
+		_, _ = writer.Write([]byte(body))
+	}))
+}
+
+func TestStreamJSON(t *testing.T) {
+	type row struct {
+		ID int `json:"id"`
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []row
+		wantErr     bool
+	}{
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1}\n{\"id\":2}\n\n{\"id\":3}\n",
+			want:        []row{{1}, {2}, {3}},
+		},
+		{
+			name:        "json lines",
+			contentType: "application/jsonl; charset=utf-8",
+			body:        `{"id":1}` + "\r\n" + `{"id":2}`,
+			want:        []row{{1}, {2}},
+		},
+		{
+			name:        "array",
+			contentType: "application/json",
+			body:        ` [{"id":1}, {"id":2}, {"id":3}] `,
+			want:        []row{{1}, {2}, {3}},
+		},
+		{
+			name:        "empty array",
+			contentType: "application/json",
+			body:        `[]`,
+			want:        nil,
+		},
+		{
+			name:        "empty body",
+			contentType: "application/x-ndjson",
+			body:        ``,
+			want:        nil,
+		},
+		{
+			name:        "single value",
+			contentType: "application/json",
+			body:        `{"id":1}`,
+			want:        []row{{1}},
+		},
+		{
+			name:        "broken record",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1}\n{\"id\":\n",
+			want:        []row{{1}},
+			wantErr:     true,
+		},
+		{
+			name:        "unclosed array",
+			contentType: "application/json",
+			body:        `[{"id":1},`,
+			want:        []row{{1}},
+			wantErr:     true,
+		},
+		{
+			name:        "data after array",
+			contentType: "application/json",
+			body:        `[{"id":1}] {}`,
+			want:        []row{{1}},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testServerStream(http.StatusOK, tt.contentType, tt.body)
+			defer server.Close()
+
+			stream := StreamJSON[row](context.Background(), NewJSON(nil), http.MethodGet, server.URL, nil)
+			defer func() {
+				_ = stream.Close()
+			}()
+			var got []row
+			for stream.Next() {
+				got = append(got, stream.Value())
+			}
+			if (stream.Err() != nil) != tt.wantErr {
+				t.Errorf("Err() error = %v, wantErr %v", stream.Err(), tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Next() got = %v, want %v", got, tt.want)
+			}
+			if stream.Next() {
+				t.Errorf("Next() after the end")
+			}
+		})
+	}
+}
+
+func TestStreamJSON_status(t *testing.T) {
+	server := testServerStream(http.StatusBadRequest, "application/json", `{"error":"bad"}`)
+	defer server.Close()
+
+	stream := StreamJSON[int](context.Background(), NewJSON(nil), http.MethodGet, server.URL, nil)
+	if stream.Next() {
+		t.Errorf("Next() wanted false")
+	}
+	cErr := new(Error)
+	if !errors.As(stream.Err(), &cErr) || !cErr.IsStatusCode() {
+		t.Errorf("Err() wrong error = %v", stream.Err())
+		return
+	}
+	if string(cErr.Body) != `{"error":"bad"}` {
+		t.Errorf("Err() wrong body = %s", cErr.Body)
+	}
+}
+
+func TestStreamJSON_maxResponseSize(t *testing.T) {
+	body := strings.Repeat("{\"id\":1}\n", 100)
+	server := testServerStream(http.StatusOK, "application/x-ndjson", body)
+	defer server.Close()
+
+	stream := StreamJSON[map[string]int](
+		context.Background(),
+		NewJSON(nil, WithMaxResponseSize(100)),
+		http.MethodGet,
+		server.URL,
+		nil,
+	)
+	count := 0
+	for stream.Next() {
+		count++
+	}
+	if !errors.Is(stream.Err(), ErrResponseTooLarge) {
+		t.Errorf("Err() wrong error = %v", stream.Err())
+	}
+	if count != 11 {
+		t.Errorf("Next() wrong count = %d", count)
+	}
+}
+
+func TestStream_All(t *testing.T) {
+	server := testServerStream(http.StatusOK, "application/json", `[1,2,3,"4"]`)
+	defer server.Close()
+
+	client := NewGenericJSONClient[int](NewJSON(nil))
+	var (
+		got  []int
+		errs []error
+	)
+	client.Stream(context.Background(), http.MethodGet, server.URL, nil).All()(func(value int, err error) bool {
+		if err != nil {
+			errs = append(errs, err)
+			return true
+		}
+		got = append(got, value)
+		return true
+	})
+	if !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("All() got = %v", got)
+	}
+	if len(errs) != 1 {
+		t.Errorf("All() wrong errors = %v", errs)
+	}
+
+	got = nil
+	client.Stream(context.Background(), http.MethodGet, server.URL, nil).All()(func(value int, err error) bool {
+		got = append(got, value)
+		return false
+	})
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("All() break got = %v", got)
+	}
+}
+
+func ExampleStreamJSON() {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = writer.Write([]byte("{\"id\":1,\"name\":\"doggie\"}\n{\"id\":2,\"name\":\"kitty\"}\n"))
+	}))
+	defer server.Close()
+
+	type Pet struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	stream := StreamJSON[Pet](context.Background(), NewJSON(nil), http.MethodGet, server.URL, nil)
+	defer func() {
+		_ = stream.Close()
+	}()
+	for stream.Next() {
+		fmt.Println(stream.Value().Name)
+	}
+	if err := stream.Err(); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// doggie
+	// kitty
+}
@@ -0,0 +1,315 @@
+package chttp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Template expands the URI Template (RFC 6570, levels 1-4) with the given variables
+// and could be used as the url argument of any client method:
+//
+//	url, err := chttp.Template("/pet/{petId}{?tags*}", map[string]interface{}{
+//		"petId": 10,
+//		"tags":  []string{"dog", "cat"},
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	err = client.GET(ctx, url, nil, &pet)
+//
+// Variable values could be strings, numbers, booleans, slices or arrays (lists), maps (associative arrays,
+// expanded in the order of the sorted keys), or pointers to them. Nil values, empty lists and maps are undefined.
+// In case of an error, the partially expanded result will be returned with the invalid expressions copied as is.
+func Template(template string, vars map[string]interface{}) (string, error) {
+	var (
+		result strings.Builder
+		first  error
+	)
+	for len(template) > 0 {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			result.WriteString(encodeTemplate(template, true))
+			break
+		}
+		result.WriteString(encodeTemplate(template[:start], true))
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			result.WriteString(template[start:])
+			if first == nil {
+				first = fmt.Errorf("uri template: unclosed expression: %q", template[start:])
+			}
+			break
+		}
+		expression := template[start : start+end+1]
+		if err := expandExpression(&result, expression[1:len(expression)-1], vars); err != nil {
+			result.WriteString(expression)
+			if first == nil {
+				first = fmt.Errorf("uri template: invalid expression %q: %w", expression, err)
+			}
+		}
+		template = template[start+end+1:]
+	}
+	return result.String(), first
+}
+
+type templateOperator struct {
+	first    string
+	sep      string
+	named    bool
+	ifEmpty  string
+	reserved bool
+}
+
+var templateOperators = map[byte]templateOperator{
+	'+': {first: "", sep: ",", reserved: true},
+	'#': {first: "#", sep: ",", reserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+}
+
+type templateVariable struct {
+	name    string
+	explode bool
+	prefix  int
+}
+
+func expandExpression(result *strings.Builder, expression string, vars map[string]interface{}) error {
+	if expression == "" {
+		return fmt.Errorf("empty expression")
+	}
+	operator := templateOperator{sep: ","}
+	if op, ok := templateOperators[expression[0]]; ok {
+		operator = op
+		expression = expression[1:]
+	} else if strings.IndexByte("=,!@|", expression[0]) >= 0 {
+		return fmt.Errorf("reserved operator %q", expression[0])
+	}
+	variables, err := parseTemplateVariables(expression)
+	if err != nil {
+		return err
+	}
+
+	var expanded strings.Builder
+	defined := false
+	for _, variable := range variables {
+		value, ok := templateValue(vars[variable.name])
+		if !ok {
+			continue
+		}
+		if defined {
+			expanded.WriteString(operator.sep)
+		} else {
+			expanded.WriteString(operator.first)
+			defined = true
+		}
+		if err = expandVariable(&expanded, operator, variable, value); err != nil {
+			return err
+		}
+	}
+	result.WriteString(expanded.String())
+	return nil
+}
+
+func parseTemplateVariables(expression string) ([]templateVariable, error) {
+	specs := strings.Split(expression, ",")
+	variables := make([]templateVariable, 0, len(specs))
+	for _, spec := range specs {
+		var variable templateVariable
+		if strings.HasSuffix(spec, "*") {
+			variable.explode = true
+			spec = spec[:len(spec)-1]
+		} else if index := strings.IndexByte(spec, ':'); index >= 0 {
+			prefix, err := strconv.Atoi(spec[index+1:])
+			if err != nil || prefix <= 0 || prefix >= 10000 {
+				return nil, fmt.Errorf("invalid prefix modifier %q", spec[index:])
+			}
+			variable.prefix = prefix
+			spec = spec[:index]
+		}
+		if !isTemplateVarName(spec) {
+			return nil, fmt.Errorf("invalid variable name %q", spec)
+		}
+		variable.name = spec
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+func isTemplateVarName(name string) bool {
+	if name == "" || name[0] == '.' || name[len(name)-1] == '.' || strings.Contains(name, "..") {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case isAlphaNum(c), c == '_', c == '.':
+		case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// templateValue converts the variable value to the one of: string, []string, or [][2]string for associative arrays.
+func templateValue(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return nil, false
+		}
+		list := make([]string, rv.Len())
+		for i := range list {
+			list[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return list, true
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return nil, false
+		}
+		pairs := make([][2]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			pairs = append(pairs, [2]string{fmt.Sprint(iter.Key().Interface()), fmt.Sprint(iter.Value().Interface())})
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i][0] < pairs[j][0]
+		})
+		return pairs, true
+	default:
+		return fmt.Sprint(rv.Interface()), true
+	}
+}
+
+func expandVariable(
+	result *strings.Builder,
+	operator templateOperator,
+	variable templateVariable,
+	value interface{},
+) error {
+	switch value := value.(type) {
+	case string:
+		if variable.prefix > 0 {
+			value = truncateRunes(value, variable.prefix)
+		}
+		writeTemplatePair(result, operator, variable.name, encodeTemplate(value, operator.reserved))
+	case []string:
+		if variable.prefix > 0 {
+			return fmt.Errorf("prefix modifier is not applicable to the list %q", variable.name)
+		}
+		if !variable.explode {
+			encoded := make([]string, len(value))
+			for i, item := range value {
+				encoded[i] = encodeTemplate(item, operator.reserved)
+			}
+			writeTemplatePair(result, operator, variable.name, strings.Join(encoded, ","))
+			return nil
+		}
+		for i, item := range value {
+			if i > 0 {
+				result.WriteString(operator.sep)
+			}
+			writeTemplatePair(result, operator, variable.name, encodeTemplate(item, operator.reserved))
+		}
+	case [][2]string:
+		if variable.prefix > 0 {
+			return fmt.Errorf("prefix modifier is not applicable to the associative array %q", variable.name)
+		}
+		if !variable.explode {
+			encoded := make([]string, 0, 2*len(value))
+			for _, pair := range value {
+				encoded = append(encoded,
+					encodeTemplate(pair[0], operator.reserved),
+					encodeTemplate(pair[1], operator.reserved),
+				)
+			}
+			writeTemplatePair(result, operator, variable.name, strings.Join(encoded, ","))
+			return nil
+		}
+		for i, pair := range value {
+			if i > 0 {
+				result.WriteString(operator.sep)
+			}
+			key := encodeTemplate(pair[0], operator.reserved)
+			if operator.named {
+				writeTemplatePair(result, operator, key, encodeTemplate(pair[1], operator.reserved))
+			} else {
+				result.WriteString(key + "=" + encodeTemplate(pair[1], operator.reserved))
+			}
+		}
+	}
+	return nil
+}
+
+func writeTemplatePair(result *strings.Builder, operator templateOperator, name string, value string) {
+	if !operator.named {
+		result.WriteString(value)
+		return
+	}
+	result.WriteString(name)
+	if value == "" {
+		result.WriteString(operator.ifEmpty)
+		return
+	}
+	result.WriteString("=" + value)
+}
+
+func encodeTemplate(value string, reserved bool) string {
+	const hex = "0123456789ABCDEF"
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case isAlphaNum(c) || strings.IndexByte("-._~", c) >= 0:
+			result.WriteByte(c)
+		case reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			result.WriteByte(c)
+		case reserved && c == '%' && i+2 < len(value) && isHex(value[i+1]) && isHex(value[i+2]):
+			result.WriteString(value[i : i+3])
+			i += 2
+		default:
+			result.WriteByte('%')
+			result.WriteByte(hex[c>>4])
+			result.WriteByte(hex[c&0x0f])
+		}
+	}
+	return result.String()
+}
+
+func truncateRunes(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+	for i := range value {
+		if length == 0 {
+			return value[:i]
+		}
+		length--
+	}
+	return value
+}
+
+func isAlphaNum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,197 @@
+package chttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	vars := map[string]interface{}{
+		"count":      []string{"one", "two", "three"},
+		"dom":        []string{"example", "com"},
+		"dub":        "me/too",
+		"hello":      "Hello World!",
+		"half":       "50%",
+		"var":        "value",
+		"who":        "fred",
+		"base":       "http://example.com/home/",
+		"path":       "/foo/bar",
+		"list":       []string{"red", "green", "blue"},
+		"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"v":          6,
+		"x":          1024,
+		"y":          "768",
+		"empty":      "",
+		"empty_keys": map[string]string{},
+		"undef":      nil,
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		// Level 1
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"O{undef}X", "OX"},
+		{"{x,y}", "1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"?{x,empty}", "?1024,"},
+		{"?{x,undef}", "?1024"},
+		{"?{undef,y}", "?768"},
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		// Level 2
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+half}", "50%25"},
+		{"{base}index", "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{"{+base}index", "http://example.com/home/index"},
+		{"O{+empty}X", "OX"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"up{+path}{var}/here", "up/foo/barvalue/here"},
+		{"{+x,hello,y}", "1024,Hello%20World!,768"},
+		{"{+path,x}/here", "/foo/bar,1024/here"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{+list*}", "red,green,blue"},
+		{"{+keys*}", "comma=,,dot=.,semi=;"},
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"X{#var}", "X#value"},
+		{"{#path:6}/here", "#/foo/b/here"},
+		{"{#list*}", "#red,green,blue"},
+		{"{#keys}", "#comma,,,dot,.,semi,;"},
+		// Level 3
+		{"{.who}", ".fred"},
+		{"{.who,who}", ".fred.fred"},
+		{"{.half,who}", ".50%25.fred"},
+		{"www{.dom*}", "www.example.com"},
+		{"X{.var}", "X.value"},
+		{"X{.empty}", "X."},
+		{"X{.undef}", "X"},
+		{"X{.var:3}", "X.val"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.keys*}", "X.comma=%2C.dot=..semi=%3B"},
+		{"X{.empty_keys*}", "X"},
+		{"{/who}", "/fred"},
+		{"{/who,who}", "/fred/fred"},
+		{"{/half,who}", "/50%25/fred"},
+		{"{/who,dub}", "/fred/me%2Ftoo"},
+		{"{/var}", "/value"},
+		{"{/var,empty}", "/value/"},
+		{"{/var,undef}", "/value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/var:1,var}", "/v/value"},
+		{"{/list}", "/red,green,blue"},
+		{"{/list*}", "/red/green/blue"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{;who}", ";who=fred"},
+		{"{;half}", ";half=50%25"},
+		{"{;empty}", ";empty"},
+		{"{;v,empty,who}", ";v=6;empty;who=fred"},
+		{"{;v,bar,who}", ";v=6;who=fred"},
+		{"{;x,y}", ";x=1024;y=768"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{;x,y,undef}", ";x=1024;y=768"},
+		{"{;hello:5}", ";hello=Hello"},
+		{"{;list}", ";list=red,green,blue"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+		{"{?who}", "?who=fred"},
+		{"{?half}", "?half=50%25"},
+		{"{?x,y}", "?x=1024&y=768"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?x,y,undef}", "?x=1024&y=768"},
+		{"{?var:3}", "?var=val"},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys}", "?keys=comma,%2C,dot,.,semi,%3B"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"{&who}", "&who=fred"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&x,y,empty}", "&x=1024&y=768&empty="},
+		{"{&var:3}", "&var=val"},
+		{"{&list*}", "&list=red&list=green&list=blue"},
+		{"{&keys*}", "&comma=%2C&dot=.&semi=%3B"},
+		// Literals
+		{"/pet/{who}/photo uploads", "/pet/fred/photo%20uploads"},
+		{"/pet/{.var}", "/pet/.value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Template(tt.template, vars)
+			if err != nil {
+				t.Errorf("Template() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Template() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_errors(t *testing.T) {
+	vars := map[string]interface{}{
+		"var":  "value",
+		"list": []string{"a", "b"},
+	}
+	tests := []struct {
+		template string
+		want     string
+		wantErr  string
+	}{
+		{template: "/pet/{var", want: "/pet/{var", wantErr: `uri template: unclosed expression: "{var"`},
+		{template: "/pet/{}/{var}", want: "/pet/{}/value", wantErr: `uri template: invalid expression "{}"`},
+		{template: "/pet/{=var}", want: "/pet/{=var}", wantErr: `uri template: invalid expression "{=var}"`},
+		{template: "/pet/{va r}", want: "/pet/{va r}", wantErr: `uri template: invalid expression "{va r}"`},
+		{template: "/pet/{var:0}", want: "/pet/{var:0}", wantErr: `uri template: invalid expression "{var:0}"`},
+		{template: "/pet/{var:x}", want: "/pet/{var:x}", wantErr: `uri template: invalid expression "{var:x}"`},
+		{template: "/pet/{list:1}", want: "/pet/{list:1}", wantErr: `uri template: invalid expression "{list:1}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Template(tt.template, vars)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Template() error = %v, want %s", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Template() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+		_, _ = writer.Write([]byte(`"` + request.URL.RequestURI() + `"`))
+	}))
+	defer server.Close()
+
+	client := NewGenericJSONClient[string](NewJSON(nil, WithBaseURL(server.URL)))
+	url, err := Template("/pet/{petId}{?tags*}", map[string]interface{}{
+		"petId": "a/b",
+		"tags":  []string{"dog", "good boy"},
+	})
+	if err != nil {
+		t.Fatalf("Template() error = %v", err)
+	}
+	got, err := client.GET(context.Background(), url, nil)
+	if err != nil {
+		t.Errorf("GET() error = %v", err)
+		return
+	}
+	if want := "/pet/a%2Fb?tags=dog&tags=good%20boy"; got != want {
+		t.Errorf("GET() = %q, want %q", got, want)
+	}
+}
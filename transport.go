@@ -21,23 +21,15 @@ type transport struct {
 }
 
 func (t *transport) RoundTrip(request *http.Request) (*http.Response, error) {
-	middlewares := t.Client.getMiddlewares()
-	var next func(request *http.Request) (*http.Response, error)
-	next = func(request *http.Request) (*http.Response, error) {
-		var middleware Middleware
-		if len(middlewares) == 0 {
-			middleware = t.getDefault()
-		} else {
-			middleware = middlewares[0]
-			middlewares = middlewares[1:]
-		}
-		return middleware(request, next)
-	}
-	return next(request)
+	return t.next(t.Client.getMiddlewares(), 0)(request)
 }
 
-func (t *transport) getDefault() Middleware {
-	return func(request *http.Request, _ func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
-		return t.Default.RoundTrip(request)
+// next returns the handler of the middleware on the given position, so any middleware could call it several times.
+func (t *transport) next(middlewares []Middleware, index int) func(request *http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		if index >= len(middlewares) {
+			return t.Default.RoundTrip(request)
+		}
+		return middlewares[index](request, t.next(middlewares, index+1))
 	}
 }
@@ -0,0 +1,363 @@
+package tus
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spyzhov/chttp"
+)
+
+const (
+	// Version is the supported version of the tus protocol.
+	Version = "1.0.0"
+	// DefaultChunkSize is the default size of the PATCH request body.
+	DefaultChunkSize = 4 << 20
+
+	offsetContentType   = "application/offset+octet-stream"
+	statusChecksumError = 460
+)
+
+var (
+	// ErrUploadNotFound is returned if the upload does not exist or was terminated.
+	ErrUploadNotFound = errors.New("tus: upload not found")
+	// ErrChecksumMismatch is returned if the server rejects the chunk checksum after all retries.
+	ErrChecksumMismatch = errors.New("tus: checksum mismatch")
+	// ErrUnsupportedChecksum is returned if the checksum algorithm is unknown.
+	ErrUnsupportedChecksum = errors.New("tus: unsupported checksum algorithm")
+	// ErrProtocol is returned if the server response violates the tus protocol.
+	ErrProtocol = errors.New("tus: protocol error")
+)
+
+var checksums = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Config is a configuration of the Client.
+type Config struct {
+	// Header is a list of the additional request headers.
+	Header http.Header
+	// ChunkSize is the maximal size of the PATCH request body, DefaultChunkSize by default.
+	// The chunk is buffered in memory.
+	ChunkSize int64
+	// Store persists the upload urls, so the interrupted uploads are resumed after the process restart.
+	// Uploads are not resumed if it's nil.
+	Store Store
+	// Checksum is the algorithm of the `Upload-Checksum` header of the checksum extension: md5, sha1, sha256,
+	// or sha512. Empty value disables checksums.
+	Checksum string
+	// Retries is the number of the attempts to resend the chunk after the checksum mismatch or the offset
+	// conflict, and to continue the upload after the failed PATCH request.
+	Retries int
+	// Progress is called after each uploaded chunk with the offset and the size of the upload.
+	Progress func(offset int64, size int64)
+}
+
+// Capabilities is the list of the server capabilities discovered with the OPTIONS request.
+type Capabilities struct {
+	// Versions is the list of the supported protocol versions.
+	Versions []string
+	// Extensions is the list of the supported extensions, e.g. creation, checksum, termination.
+	Extensions []string
+	// MaxSize is the maximal size of the upload, zero if it's unknown.
+	MaxSize int64
+	// Checksums is the list of the supported checksum algorithms.
+	Checksums []string
+}
+
+// Client is the tus 1.0 client. All requests are sent with the chttp.Client, so they go through
+// the client middlewares.
+type Client struct {
+	client   *chttp.Client
+	endpoint string
+	config   Config
+}
+
+// NewClient creates the Client for the creation endpoint of the server. Relative endpoint is resolved against
+// the client base URL.
+func NewClient(client *chttp.Client, endpoint string, config Config) *Client {
+	if client == nil {
+		client = chttp.NewClient(nil)
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = DefaultChunkSize
+	}
+	return &Client{
+		client:   client,
+		endpoint: endpoint,
+		config:   config,
+	}
+}
+
+// Options discovers the server capabilities.
+func (c *Client) Options(ctx context.Context) (*Capabilities, error) {
+	response, err := c.request(http.MethodOptions, c.endpoint).
+		Expect(http.StatusOK, http.StatusNoContent).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = response.Body.Close()
+	capabilities := &Capabilities{
+		Versions:   splitList(response.Header.Get("Tus-Version")),
+		Extensions: splitList(response.Header.Get("Tus-Extension")),
+		Checksums:  splitList(response.Header.Get("Tus-Checksum-Algorithm")),
+	}
+	if value := response.Header.Get("Tus-Max-Size"); value != "" {
+		if capabilities.MaxSize, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid Tus-Max-Size: %q", ErrProtocol, value)
+		}
+	}
+	return capabilities, nil
+}
+
+// Create creates the upload on the server with the creation extension and returns its url.
+// The url is saved in the Store, if the upload has the fingerprint.
+func (c *Client) Create(ctx context.Context, upload *Upload) (string, error) {
+	request := c.request(http.MethodPost, c.endpoint).
+		Header("Upload-Length", strconv.FormatInt(upload.Size, 10)).
+		Expect(http.StatusCreated)
+	if len(upload.Metadata) > 0 {
+		request.Header("Upload-Metadata", encodeMetadata(upload.Metadata))
+	}
+	response, err := request.Do(ctx)
+	if err != nil {
+		return "", err
+	}
+	_ = response.Body.Close()
+	location, err := response.Location()
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid Location: %v", ErrProtocol, err)
+	}
+	url := location.String()
+	if c.config.Store != nil && upload.Fingerprint != "" {
+		if err = c.config.Store.Set(upload.Fingerprint, url); err != nil {
+			return "", fmt.Errorf("tus: saving upload url: %w", err)
+		}
+	}
+	return url, nil
+}
+
+// Offset returns the offset and the length of the upload. Returns ErrUploadNotFound, if the upload
+// does not exist.
+func (c *Client) Offset(ctx context.Context, url string) (offset int64, length int64, err error) {
+	response, err := c.request(http.MethodHead, url).
+		Header("Cache-Control", "no-store").
+		Expect(http.StatusOK, http.StatusNoContent).
+		Do(ctx)
+	if err != nil {
+		return 0, 0, notFound(err)
+	}
+	_ = response.Body.Close()
+	if offset, err = headerInt(response, "Upload-Offset"); err != nil {
+		return 0, 0, err
+	}
+	length = -1
+	if response.Header.Get("Upload-Length") != "" {
+		if length, err = headerInt(response, "Upload-Length"); err != nil {
+			return 0, 0, err
+		}
+	}
+	return offset, length, nil
+}
+
+// Upload uploads the content. If the Store contains the url of the upload with the same fingerprint,
+// the upload is resumed from the offset reported by the server, otherwise the new upload is created.
+// The url is removed from the Store after the successful upload.
+func (c *Client) Upload(ctx context.Context, upload *Upload) (string, error) {
+	url, offset, err := c.resume(ctx, upload)
+	if err != nil {
+		return "", err
+	}
+	if err = c.uploadChunks(ctx, url, offset, upload); err != nil {
+		return url, err
+	}
+	if c.config.Store != nil && upload.Fingerprint != "" {
+		if err = c.config.Store.Delete(upload.Fingerprint); err != nil {
+			return url, fmt.Errorf("tus: removing upload url: %w", err)
+		}
+	}
+	return url, nil
+}
+
+// Terminate terminates the upload with the termination extension.
+func (c *Client) Terminate(ctx context.Context, url string) error {
+	response, err := c.request(http.MethodDelete, url).
+		Expect(http.StatusNoContent, http.StatusOK).
+		Do(ctx)
+	if err != nil {
+		return notFound(err)
+	}
+	return response.Body.Close()
+}
+
+// resume returns the url and the offset of the stored upload, or creates the new one.
+func (c *Client) resume(ctx context.Context, upload *Upload) (string, int64, error) {
+	if c.config.Store != nil && upload.Fingerprint != "" {
+		url, ok, err := c.config.Store.Get(upload.Fingerprint)
+		if err != nil {
+			return "", 0, fmt.Errorf("tus: loading upload url: %w", err)
+		}
+		if ok {
+			offset, _, err := c.Offset(ctx, url)
+			if err == nil {
+				return url, offset, nil
+			}
+			if !errors.Is(err, ErrUploadNotFound) {
+				return "", 0, err
+			}
+			if err = c.config.Store.Delete(upload.Fingerprint); err != nil {
+				return "", 0, fmt.Errorf("tus: removing upload url: %w", err)
+			}
+		}
+	}
+	url, err := c.Create(ctx, upload)
+	return url, 0, err
+}
+
+// uploadChunks sends the content from the offset with the PATCH requests.
+func (c *Client) uploadChunks(ctx context.Context, url string, offset int64, upload *Upload) error {
+	buffer := make([]byte, c.config.ChunkSize)
+	failures := 0
+	for offset < upload.Size {
+		size := upload.Size - offset
+		if size > c.config.ChunkSize {
+			size = c.config.ChunkSize
+		}
+		chunk := buffer[:size]
+		if _, err := upload.Reader.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return fmt.Errorf("tus: reading upload: %w", err)
+		}
+		next, err := c.patch(ctx, url, offset, chunk)
+		if err != nil {
+			if failures >= c.config.Retries || ctx.Err() != nil || !retryable(err) {
+				return err
+			}
+			failures++
+			if offset, _, err = c.Offset(ctx, url); err != nil {
+				return err
+			}
+			continue
+		}
+		failures = 0
+		offset = next
+		if c.config.Progress != nil {
+			c.config.Progress(offset, upload.Size)
+		}
+	}
+	return nil
+}
+
+// patch sends the chunk and returns the new offset.
+func (c *Client) patch(ctx context.Context, url string, offset int64, chunk []byte) (int64, error) {
+	request := c.request(http.MethodPatch, url).
+		Header("Content-Type", offsetContentType).
+		Header("Upload-Offset", strconv.FormatInt(offset, 10)).
+		Body(chunk).
+		Expect(http.StatusNoContent, http.StatusOK)
+	if c.config.Checksum != "" {
+		checksum, err := uploadChecksum(c.config.Checksum, chunk)
+		if err != nil {
+			return 0, err
+		}
+		request.Header("Upload-Checksum", checksum)
+	}
+	response, err := request.Do(ctx)
+	if err != nil {
+		if statusCode(err) == statusChecksumError {
+			return 0, fmt.Errorf("%w: %v", ErrChecksumMismatch, err)
+		}
+		return 0, notFound(err)
+	}
+	_ = response.Body.Close()
+	next, err := headerInt(response, "Upload-Offset")
+	if err != nil {
+		return 0, err
+	}
+	if next <= offset || next > offset+int64(len(chunk)) {
+		return 0, fmt.Errorf("%w: unexpected Upload-Offset %d after %d", ErrProtocol, next, offset)
+	}
+	return next, nil
+}
+
+// request creates the request with the protocol version and the configured headers.
+func (c *Client) request(method string, url string) *chttp.RequestBuilder {
+	request := c.client.NewRequest(method, url).Header("Tus-Resumable", Version)
+	for name, values := range c.config.Header {
+		for _, value := range values {
+			request.Header(name, value)
+		}
+	}
+	return request
+}
+
+func uploadChecksum(algorithm string, data []byte) (string, error) {
+	constructor, ok := checksums[strings.ToLower(algorithm)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedChecksum, algorithm)
+	}
+	digest := constructor()
+	_, _ = digest.Write(data)
+	return strings.ToLower(algorithm) + " " + base64.StdEncoding.EncodeToString(digest.Sum(nil)), nil
+}
+
+// retryable reports whether the upload could be continued after the error of the PATCH request.
+func retryable(err error) bool {
+	if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, chttp.ErrConflict) {
+		return true
+	}
+	cErr := new(chttp.Error)
+	if errors.As(err, &cErr) {
+		return cErr.Retryable()
+	}
+	urlErr := new(neturl.Error)
+	return errors.As(err, &urlErr)
+}
+
+// notFound replaces the 404 and 410 status errors with the ErrUploadNotFound.
+func notFound(err error) error {
+	if code := statusCode(err); code == http.StatusNotFound || code == http.StatusGone {
+		return fmt.Errorf("%w: %v", ErrUploadNotFound, err)
+	}
+	return err
+}
+
+func statusCode(err error) int {
+	cErr := new(chttp.Error)
+	if errors.As(err, &cErr) && cErr.IsStatusCode() {
+		return cErr.Response.StatusCode
+	}
+	return 0
+}
+
+func headerInt(response *http.Response, name string) (int64, error) {
+	value, err := strconv.ParseInt(response.Header.Get(name), 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%w: invalid %s: %q", ErrProtocol, name, response.Header.Get(name))
+	}
+	return value, nil
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
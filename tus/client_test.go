@@ -0,0 +1,217 @@
+package tus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spyzhov/chttp"
+)
+
+func testData(size int) []byte {
+	return bytes.Repeat([]byte("0123456789"), size/10)
+}
+
+func TestClient_Upload(t *testing.T) {
+	data := testData(250)
+	tests := []struct {
+		name             string
+		config           Config
+		checksumFailures int
+		maxPatch         int
+		want             []string
+		wantErr          error
+	}{
+		{
+			name:   "single chunk",
+			config: Config{},
+			want:   []string{"POST /files", "PATCH /files/1 0"},
+		},
+		{
+			name:   "chunks with checksum",
+			config: Config{ChunkSize: 100, Checksum: "sha1"},
+			want:   []string{"POST /files", "PATCH /files/1 0", "PATCH /files/1 100", "PATCH /files/1 200"},
+		},
+		{
+			name:     "partial chunks",
+			config:   Config{ChunkSize: 100},
+			maxPatch: 60,
+			want: []string{
+				"POST /files", "PATCH /files/1 0", "PATCH /files/1 60", "PATCH /files/1 120", "PATCH /files/1 180",
+				"PATCH /files/1 240",
+			},
+		},
+		{
+			name:             "checksum retry",
+			config:           Config{ChunkSize: 200, Checksum: "SHA1", Retries: 1},
+			checksumFailures: 1,
+			want:             []string{"POST /files", "PATCH /files/1 0", "HEAD /files/1", "PATCH /files/1 0", "PATCH /files/1 200"},
+		},
+		{
+			name:             "checksum mismatch",
+			config:           Config{Checksum: "sha1"},
+			checksumFailures: 1,
+			want:             []string{"POST /files", "PATCH /files/1 0"},
+			wantErr:          ErrChecksumMismatch,
+		},
+		{
+			name:    "unsupported checksum",
+			config:  Config{Checksum: "crc32"},
+			want:    []string{"POST /files"},
+			wantErr: ErrUnsupportedChecksum,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer()
+			defer server.Close()
+			server.checksumFailures = tt.checksumFailures
+			server.maxPatch = tt.maxPatch
+
+			client := chttp.NewClient(nil, chttp.WithBaseURL(server.URL))
+			client.With(func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+				request.Header.Set("Authorization", "Bearer token")
+				return next(request)
+			})
+			url, err := NewClient(client, "/files", tt.config).Upload(context.Background(), &Upload{
+				Reader:   bytes.NewReader(data),
+				Size:     int64(len(data)),
+				Metadata: map[string]string{"filename": "data.txt", "public": ""},
+			})
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("Upload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := server.log(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Upload() requests = %q, want %q", got, tt.want)
+			}
+			for _, header := range server.headers {
+				if header.Get("Authorization") != "Bearer token" {
+					t.Errorf("Upload() request without the middleware header: %v", header)
+				}
+			}
+			if err != nil {
+				return
+			}
+			upload := server.upload("1")
+			if url != server.URL+"/files/1" || !bytes.Equal(upload.data, data) {
+				t.Errorf("Upload() url = %s, data = %q", url, upload.data)
+			}
+			if upload.metadata != "filename ZGF0YS50eHQ=,public" {
+				t.Errorf("Upload() metadata = %q", upload.metadata)
+			}
+		})
+	}
+}
+
+func TestClient_Upload_resume(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "data.txt")
+	data := testData(1000)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	storePath := filepath.Join(t.TempDir(), "uploads.json")
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+	upload, err := NewUploadFromFile(file)
+	if err != nil {
+		t.Fatalf("NewUploadFromFile() error = %v", err)
+	}
+
+	// the process is interrupted after the second chunk
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = NewClient(nil, server.URL+"/files", Config{
+		ChunkSize: 300,
+		Store:     NewFileStore(storePath),
+		Progress: func(offset int64, size int64) {
+			if offset >= 600 {
+				cancel()
+			}
+		},
+	}).Upload(ctx, upload)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	// the new process resumes the upload with the same store
+	var offsets []int64
+	url, err := NewClient(nil, server.URL+"/files", Config{
+		ChunkSize: 300,
+		Store:     NewFileStore(storePath),
+		Progress: func(offset int64, size int64) {
+			offsets = append(offsets, offset)
+		},
+	}).Upload(context.Background(), upload)
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if !reflect.DeepEqual(offsets, []int64{900, 1000}) {
+		t.Errorf("Upload() was not resumed, offsets = %v, requests = %q", offsets, server.log())
+	}
+	if !bytes.Equal(server.upload("1").data, data) || !strings.HasSuffix(url, "/files/1") {
+		t.Errorf("Upload() url = %s, wrong data", url)
+	}
+	if _, ok, _ := NewFileStore(storePath).Get(upload.Fingerprint); ok {
+		t.Errorf("Upload() url was not removed from the store")
+	}
+}
+
+func TestClient_Terminate(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	store := NewMemoryStore()
+	client := NewClient(nil, server.URL+"/files", Config{Store: store})
+	upload := &Upload{Reader: bytes.NewReader(nil), Size: 10, Fingerprint: "data"}
+
+	url, err := client.Create(context.Background(), upload)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if offset, length, err := client.Offset(context.Background(), url); err != nil || offset != 0 || length != 10 {
+		t.Errorf("Offset() = %d, %d, error = %v", offset, length, err)
+	}
+	if err = client.Terminate(context.Background(), url); err != nil {
+		t.Fatalf("Terminate() error = %v", err)
+	}
+	if _, _, err = client.Offset(context.Background(), url); !errors.Is(err, ErrUploadNotFound) {
+		t.Errorf("Offset() error = %v", err)
+	}
+	if err = client.Terminate(context.Background(), url); !errors.Is(err, ErrUploadNotFound) {
+		t.Errorf("Terminate() error = %v", err)
+	}
+
+	// the terminated upload is created again
+	upload.Reader = bytes.NewReader(testData(10))
+	url, err = client.Upload(context.Background(), upload)
+	if err != nil || !strings.HasSuffix(url, "/files/2") {
+		t.Errorf("Upload() url = %s, error = %v", url, err)
+	}
+}
+
+func TestClient_Options(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	capabilities, err := NewClient(nil, server.URL+"/files", Config{}).Options(context.Background())
+	if err != nil {
+		t.Fatalf("Options() error = %v", err)
+	}
+	want := &Capabilities{
+		Versions:   []string{"1.0.0", "0.2.2"},
+		Extensions: []string{"creation", "checksum", "termination"},
+		MaxSize:    1 << 30,
+		Checksums:  []string{"sha1"},
+	}
+	if !reflect.DeepEqual(capabilities, want) {
+		t.Errorf("Options() = %+v, want %+v", capabilities, want)
+	}
+}
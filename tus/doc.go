@@ -0,0 +1,2 @@
+// Package tus provides the resumable uploads client (tus 1.0) on top of the cHTTP clients.
+package tus
@@ -0,0 +1,140 @@
+package tus
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type testUpload struct {
+	length   int64
+	metadata string
+	data     []byte
+}
+
+// testServer is the in-memory tus 1.0 server with the creation, checksum, and termination extensions.
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	uploads  map[string]*testUpload
+	created  int
+	requests []string
+	headers  []http.Header
+	// checksumFailures is the number of the next PATCH requests rejected with the checksum mismatch.
+	checksumFailures int
+	// maxPatch is the maximal number of bytes accepted by the PATCH request, zero means no limit.
+	maxPatch int
+}
+
+func newTestServer() *testServer {
+	server := &testServer{uploads: make(map[string]*testUpload)}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+func (s *testServer) upload(id string) *testUpload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.uploads[id]
+}
+
+func (s *testServer) log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *testServer) handle(writer http.ResponseWriter, request *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := request.Method + " " + request.URL.Path
+	if offset := request.Header.Get("Upload-Offset"); offset != "" {
+		entry += " " + offset
+	}
+	s.requests = append(s.requests, entry)
+	s.headers = append(s.headers, request.Header.Clone())
+	writer.Header().Set("Tus-Resumable", Version)
+	if request.Method == http.MethodOptions {
+		writer.Header().Set("Tus-Version", "1.0.0,0.2.2")
+		writer.Header().Set("Tus-Extension", "creation,checksum,termination")
+		writer.Header().Set("Tus-Checksum-Algorithm", "sha1")
+		writer.Header().Set("Tus-Max-Size", "1073741824")
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if request.Header.Get("Tus-Resumable") != Version {
+		writer.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	if request.Method == http.MethodPost && request.URL.Path == "/files" {
+		length, err := strconv.ParseInt(request.Header.Get("Upload-Length"), 10, 64)
+		if err != nil {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.created++
+		id := strconv.Itoa(s.created)
+		s.uploads[id] = &testUpload{length: length, metadata: request.Header.Get("Upload-Metadata")}
+		writer.Header().Set("Location", "/files/"+id)
+		writer.WriteHeader(http.StatusCreated)
+		return
+	}
+	id := strings.TrimPrefix(request.URL.Path, "/files/")
+	upload, ok := s.uploads[id]
+	if !ok {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch request.Method {
+	case http.MethodHead:
+		writer.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+		writer.Header().Set("Upload-Length", strconv.FormatInt(upload.length, 10))
+		writer.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		s.patch(writer, request, upload)
+	case http.MethodDelete:
+		delete(s.uploads, id)
+		writer.WriteHeader(http.StatusNoContent)
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *testServer) patch(writer http.ResponseWriter, request *http.Request, upload *testUpload) {
+	if request.Header.Get("Content-Type") != offsetContentType {
+		writer.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	if request.Header.Get("Upload-Offset") != strconv.Itoa(len(upload.data)) {
+		writer.WriteHeader(http.StatusConflict)
+		return
+	}
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if checksum := request.Header.Get("Upload-Checksum"); checksum != "" {
+		sum := sha1.Sum(data)
+		if s.checksumFailures > 0 || checksum != "sha1 "+base64.StdEncoding.EncodeToString(sum[:]) {
+			s.checksumFailures--
+			writer.WriteHeader(statusChecksumError)
+			return
+		}
+	}
+	if s.maxPatch > 0 && len(data) > s.maxPatch {
+		data = data[:s.maxPatch]
+	}
+	if int64(len(upload.data)+len(data)) > upload.length {
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	upload.data = append(upload.data, data...)
+	writer.Header().Set("Upload-Offset", strconv.Itoa(len(upload.data)))
+	writer.WriteHeader(http.StatusNoContent)
+}
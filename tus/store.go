@@ -0,0 +1,141 @@
+package tus
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the urls of the uploads by their fingerprints, so the interrupted uploads could be resumed.
+type Store interface {
+	// Get returns the url of the upload with the fingerprint.
+	Get(fingerprint string) (url string, ok bool, err error)
+	// Set saves the url of the upload with the fingerprint.
+	Set(fingerprint string, url string) error
+	// Delete removes the upload with the fingerprint.
+	Delete(fingerprint string) error
+}
+
+// MemoryStore is the Store, which keeps the urls in memory.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	urls map[string]string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{urls: make(map[string]string)}
+}
+
+// Get returns the url of the upload with the fingerprint.
+func (s *MemoryStore) Get(fingerprint string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	url, ok := s.urls[fingerprint]
+	return url, ok, nil
+}
+
+// Set saves the url of the upload with the fingerprint.
+func (s *MemoryStore) Set(fingerprint string, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urls[fingerprint] = url
+	return nil
+}
+
+// Delete removes the upload with the fingerprint.
+func (s *MemoryStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.urls, fingerprint)
+	return nil
+}
+
+// FileStore is the Store, which keeps the urls in the JSON file, so the uploads could be resumed
+// after the process restart. The file is replaced atomically on every change.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore creates the FileStore with the given file path. The file is created with the first upload.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Get returns the url of the upload with the fingerprint.
+func (s *FileStore) Get(fingerprint string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	url, ok := urls[fingerprint]
+	return url, ok, nil
+}
+
+// Set saves the url of the upload with the fingerprint.
+func (s *FileStore) Set(fingerprint string, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	urls[fingerprint] = url
+	return s.write(urls)
+}
+
+// Delete removes the upload with the fingerprint.
+func (s *FileStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := urls[fingerprint]; !ok {
+		return nil
+	}
+	delete(urls, fingerprint)
+	return s.write(urls)
+}
+
+func (s *FileStore) load() (map[string]string, error) {
+	urls := make(map[string]string)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return urls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &urls); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+func (s *FileStore) write(urls map[string]string) error {
+	data, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), s.path)
+}
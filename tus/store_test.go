@@ -0,0 +1,42 @@
+package tus
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(t *testing.T) Store
+	}{
+		{name: "memory", store: func(t *testing.T) Store { return NewMemoryStore() }},
+		{name: "file", store: func(t *testing.T) Store { return NewFileStore(filepath.Join(t.TempDir(), "store.json")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store(t)
+			if _, ok, err := store.Get("a"); ok || err != nil {
+				t.Errorf("Get() ok = %v, error = %v", ok, err)
+			}
+			if err := store.Set("a", "https://example.com/files/1"); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := store.Set("b", "https://example.com/files/2"); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if url, ok, err := store.Get("a"); !ok || err != nil || url != "https://example.com/files/1" {
+				t.Errorf("Get() = %s, ok = %v, error = %v", url, ok, err)
+			}
+			if err := store.Delete("a"); err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if _, ok, _ := store.Get("a"); ok {
+				t.Errorf("Get() returned the deleted url")
+			}
+			if _, ok, _ := store.Get("b"); !ok {
+				t.Errorf("Get() lost the url")
+			}
+		})
+	}
+}
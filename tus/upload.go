@@ -0,0 +1,60 @@
+package tus
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Upload is the resource to upload.
+type Upload struct {
+	// Reader is the content of the upload.
+	Reader io.ReaderAt
+	// Size is the size of the upload.
+	Size int64
+	// Metadata is the list of the key-value pairs sent in the `Upload-Metadata` header.
+	Metadata map[string]string
+	// Fingerprint identifies the upload in the Store. Uploads without the fingerprint are not persisted.
+	Fingerprint string
+}
+
+// NewUploadFromFile creates the Upload of the file. The `filename` metadata is set to the file name, and
+// the fingerprint is based on the absolute path, size, and modification time of the file.
+func NewUploadFromFile(file *os.File) (*Upload, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Upload{
+		Reader:      file,
+		Size:        info.Size(),
+		Metadata:    map[string]string{"filename": info.Name()},
+		Fingerprint: fmt.Sprintf("%s-%d-%d", path, info.Size(), info.ModTime().UnixNano()),
+	}, nil
+}
+
+// encodeMetadata encodes the metadata into the `Upload-Metadata` header value, keys are sorted.
+func encodeMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := metadata[key]; value != "" {
+			pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+		} else {
+			pairs = append(pairs, key)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
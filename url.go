@@ -0,0 +1,57 @@
+package chttp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Path joins the given segments into the relative path, each segment will be escaped,
+// e.g. Path("pet", "a/b") returns `pet/a%2Fb`. The `.` and `..` segments are escaped as well, so they are not
+// resolved as the dot segments, and the `:` is escaped, so the path is never parsed as the url with the scheme.
+func Path(segments ...interface{}) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = escapeSegment(fmt.Sprint(segment))
+	}
+	return strings.Join(escaped, "/")
+}
+
+func escapeSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.Repeat("%2E", len(segment))
+	}
+	return strings.ReplaceAll(url.PathEscape(segment), ":", "%3A")
+}
+
+func resolveURL(base *url.URL, ref *url.URL) *url.URL {
+	result := *base
+	if base.User != nil {
+		user := *base.User
+		result.User = &user
+	}
+	if escaped := joinPath(base.EscapedPath(), ref.EscapedPath()); escaped != base.EscapedPath() {
+		path, err := url.PathUnescape(escaped)
+		if err != nil {
+			path = escaped
+		}
+		result.Path, result.RawPath = path, escaped
+	}
+	switch {
+	case ref.RawQuery == "":
+		result.ForceQuery = result.ForceQuery || ref.ForceQuery
+	case result.RawQuery == "":
+		result.RawQuery = ref.RawQuery
+	default:
+		result.RawQuery += "&" + ref.RawQuery
+	}
+	result.Fragment, result.RawFragment = ref.Fragment, ref.RawFragment
+	return &result
+}
+
+func joinPath(base, ref string) string {
+	if ref == "" {
+		return base
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(ref, "/")
+}
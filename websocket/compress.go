@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	extensionDeflate = "permessage-deflate"
+	// maxWindowSize is the maximal size of the LZ77 sliding window of the DEFLATE algorithm.
+	maxWindowSize = 1 << 15
+)
+
+// deflateTail is the end of the sync flush block removed from the compressed message (RFC 7692 7.2.1),
+// followed by the final empty stored block to finish the DEFLATE stream.
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+// deflateParams is the result of the `permessage-deflate` extension negotiation (RFC 7692).
+type deflateParams struct {
+	enabled bool
+	// serverTakeover is true if the server reuses the LZ77 sliding window between the messages.
+	serverTakeover bool
+}
+
+// deflateOffer is the extension offer of the client, the client never reuses the sliding window.
+func deflateOffer() string {
+	return extensionDeflate + "; client_no_context_takeover; server_no_context_takeover"
+}
+
+// parseDeflateResponse parses the `Sec-WebSocket-Extensions` header of the handshake response.
+func parseDeflateResponse(headers []string, offered bool) (deflateParams, error) {
+	result := deflateParams{}
+	for _, header := range headers {
+		for _, extension := range strings.Split(header, ",") {
+			params := strings.Split(extension, ";")
+			name := strings.TrimSpace(params[0])
+			if name == "" {
+				continue
+			}
+			if name != extensionDeflate || !offered || result.enabled {
+				return result, fmt.Errorf("websocket: unexpected extension %q", name)
+			}
+			result.enabled = true
+			result.serverTakeover = true
+			for _, param := range params[1:] {
+				key, _, _ := strings.Cut(strings.TrimSpace(param), "=")
+				switch strings.TrimSpace(key) {
+				case "server_no_context_takeover":
+					result.serverTakeover = false
+				case "client_no_context_takeover", "server_max_window_bits":
+				default:
+					return result, fmt.Errorf("websocket: unexpected %s parameter %q", extensionDeflate, key)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// compressMessage compresses the message as the separate DEFLATE block without the sync flush tail.
+func compressMessage(writer *flate.Writer, data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer.Reset(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), deflateTail[:4]), nil
+}
+
+// decompressMessage decompresses the message with the given sliding window.
+// Returns ErrMessageTooLarge if the decompressed message exceeds the limit, negative limit means no limit.
+func decompressMessage(payload []byte, dict []byte, limit int64) ([]byte, error) {
+	reader := flate.NewReaderDict(io.MultiReader(bytes.NewReader(payload), bytes.NewReader(deflateTail)), dict)
+	defer func() {
+		_ = reader.Close()
+	}()
+	if limit < 0 {
+		return io.ReadAll(reader)
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrMessageTooLarge
+	}
+	return data, nil
+}
+
+// slideWindow appends the data to the sliding window and keeps only the last maxWindowSize bytes of it.
+func slideWindow(window []byte, data []byte) []byte {
+	window = append(window, data...)
+	if len(window) > maxWindowSize {
+		window = append(window[:0], window[len(window)-maxWindowSize:]...)
+	}
+	return window
+}
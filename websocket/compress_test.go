@@ -0,0 +1,90 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDeflateResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		offered bool
+		want    deflateParams
+		wantErr bool
+	}{
+		{name: "empty", headers: nil, offered: true, want: deflateParams{}},
+		{
+			name:    "no context takeover",
+			headers: []string{"permessage-deflate; server_no_context_takeover; client_no_context_takeover"},
+			offered: true,
+			want:    deflateParams{enabled: true},
+		},
+		{
+			name:    "context takeover",
+			headers: []string{"permessage-deflate; server_max_window_bits=10"},
+			offered: true,
+			want:    deflateParams{enabled: true, serverTakeover: true},
+		},
+		{name: "not offered", headers: []string{"permessage-deflate"}, offered: false, wantErr: true},
+		{name: "unknown extension", headers: []string{"x-webkit-deflate-frame"}, offered: true, wantErr: true},
+		{
+			name:    "unknown parameter",
+			headers: []string{"permessage-deflate; client_max_window_bits=10"},
+			offered: true,
+			wantErr: true,
+		},
+		{name: "duplicate", headers: []string{"permessage-deflate, permessage-deflate"}, offered: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeflateResponse(tt.headers, tt.offered)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDeflateResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("parseDeflateResponse() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompressMessage_contextTakeover(t *testing.T) {
+	// the server compresses all messages with the same stream, so the next messages refer to the previous ones
+	var buffer bytes.Buffer
+	writer, _ := flate.NewWriter(&buffer, flate.BestCompression)
+	messages := []string{strings.Repeat("hello world ", 10), strings.Repeat("hello world ", 10), "bye"}
+	var window []byte
+	for _, message := range messages {
+		buffer.Reset()
+		_, _ = writer.Write([]byte(message))
+		_ = writer.Flush()
+		payload := bytes.TrimSuffix(buffer.Bytes(), deflateTail[:4])
+
+		data, err := decompressMessage(payload, window, -1)
+		if err != nil {
+			t.Fatalf("decompressMessage() error = %v", err)
+		}
+		if string(data) != message {
+			t.Errorf("decompressMessage() got = %q, want %q", data, message)
+		}
+		window = slideWindow(window, data)
+	}
+}
+
+func TestDecompressMessage_limit(t *testing.T) {
+	writer, _ := flate.NewWriter(nil, flate.DefaultCompression)
+	payload, err := compressMessage(writer, make([]byte, 1000))
+	if err != nil {
+		t.Fatalf("compressMessage() error = %v", err)
+	}
+	if _, err = decompressMessage(payload, nil, 999); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("decompressMessage() wrong error = %v", err)
+	}
+	if data, err := decompressMessage(payload, nil, 1000); err != nil || len(data) != 1000 {
+		t.Errorf("decompressMessage() got %d bytes, error = %v", len(data), err)
+	}
+}
@@ -0,0 +1,449 @@
+package websocket
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of the data message.
+type MessageType int
+
+// Data message types, the values are equal to the frame opcodes.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// Close codes defined in the RFC 6455 7.4.1.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+)
+
+var (
+	// ErrCloseSent is returned if the message is written after the close frame was sent.
+	ErrCloseSent = errors.New("websocket: close sent")
+	// ErrPongTimeout is returned if the pong frame for the keepalive ping was not received in time.
+	ErrPongTimeout = errors.New("websocket: pong timeout")
+)
+
+// CloseError is returned by the Conn.ReadMessage when the close frame is received.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Reason)
+}
+
+// Conn is the client side of the WebSocket connection. Messages should be read by one goroutine at a time, and
+// reading is required to handle the control frames: the pings are answered, and the pongs and the close frames are
+// processed by the Conn.ReadMessage. Writing methods are safe for the concurrent use.
+type Conn struct {
+	// lastPong is accessed atomically, it is the first field to be 64-bit aligned on the 32-bit platforms.
+	lastPong int64
+
+	rwc         io.ReadWriteCloser
+	reader      *bufio.Reader
+	writer      *bufio.Writer
+	config      Config
+	deflate     deflateParams
+	compressor  *flate.Writer
+	window      []byte
+	subprotocol string
+
+	readMu  sync.Mutex
+	readErr error
+
+	writeMu   sync.Mutex
+	closeSent bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func newConn(rwc io.ReadWriteCloser, config Config, deflate deflateParams, subprotocol string) *Conn {
+	conn := &Conn{
+		rwc:         rwc,
+		reader:      bufio.NewReader(rwc),
+		writer:      bufio.NewWriter(rwc),
+		config:      config,
+		deflate:     deflate,
+		subprotocol: subprotocol,
+		lastPong:    time.Now().UnixNano(),
+		done:        make(chan struct{}),
+	}
+	if config.PingInterval > 0 {
+		go conn.keepalive()
+	}
+	return conn
+}
+
+// Subprotocol returns the subprotocol selected by the server.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// Compression returns true if the `permessage-deflate` extension was negotiated.
+func (c *Conn) Compression() bool {
+	return c.deflate.enabled
+}
+
+// ReadMessage reads the next data message. Fragmented messages are reassembled, and the control frames
+// received in between are processed. Returns the *CloseError if the connection was closed with the close frame.
+// After the first error, all next calls return the same error.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	return c.read()
+}
+
+func (c *Conn) read() (MessageType, []byte, error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	messageType, data, err := c.readMessage()
+	if err != nil {
+		c.readErr = c.fail(err)
+		return 0, nil, c.readErr
+	}
+	return messageType, data, nil
+}
+
+func (c *Conn) readMessage() (MessageType, []byte, error) {
+	var (
+		opcode     byte
+		compressed bool
+		message    []byte
+	)
+	for {
+		limit := c.config.MaxMessageSize - int64(len(message))
+		if c.config.MaxMessageSize < 0 {
+			limit = -1
+		}
+		f, err := readFrame(c.reader, limit)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, nil, &CloseError{Code: CloseAbnormalClosure, Reason: err.Error()}
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if f.masked {
+			return 0, nil, protocolError("masked server frame")
+		}
+		if f.rsv1 && (!c.deflate.enabled || isControl(f.opcode) || f.opcode == opContinuation) {
+			return 0, nil, protocolError("unexpected RSV1 bit")
+		}
+		switch f.opcode {
+		case opPing:
+			if err = c.writeControl(opPong, f.payload); err != nil && !errors.Is(err, ErrCloseSent) {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			atomic.StoreInt64(&c.lastPong, time.Now().UnixNano())
+			continue
+		case opClose:
+			return 0, nil, c.receiveClose(f.payload)
+		case opText, opBinary:
+			if opcode != 0 {
+				return 0, nil, protocolError("unexpected data frame in the fragmented message")
+			}
+			opcode, compressed = f.opcode, f.rsv1
+		case opContinuation:
+			if opcode == 0 {
+				return 0, nil, protocolError("unexpected continuation frame")
+			}
+		default:
+			return 0, nil, protocolError(fmt.Sprintf("unknown opcode %d", f.opcode))
+		}
+		message = append(message, f.payload...)
+		if f.fin {
+			break
+		}
+	}
+	if compressed {
+		var err error
+		if message, err = c.decompress(message); err != nil {
+			return 0, nil, err
+		}
+	}
+	if opcode == opText && !utf8.Valid(message) {
+		return 0, nil, &CloseError{Code: CloseInvalidFramePayloadData, Reason: "invalid UTF-8 text"}
+	}
+	return MessageType(opcode), message, nil
+}
+
+func (c *Conn) decompress(payload []byte) ([]byte, error) {
+	data, err := decompressMessage(payload, c.window, c.config.MaxMessageSize)
+	if err != nil {
+		if errors.Is(err, ErrMessageTooLarge) {
+			return nil, err
+		}
+		return nil, &CloseError{Code: CloseInvalidFramePayloadData, Reason: "invalid compressed data"}
+	}
+	if c.deflate.serverTakeover {
+		c.window = slideWindow(c.window, data)
+	}
+	return data, nil
+}
+
+// receiveClose answers the close frame of the server, if it was not sent yet, and closes the connection.
+func (c *Conn) receiveClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return protocolError("invalid close frame payload")
+	case len(payload) >= 2:
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !validCloseCode(closeErr.Code) {
+			return protocolError(fmt.Sprintf("invalid close code %d", closeErr.Code))
+		}
+		if !utf8.Valid(payload[2:]) {
+			return &CloseError{Code: CloseInvalidFramePayloadData, Reason: "invalid UTF-8 close reason"}
+		}
+	}
+	code := closeErr.Code
+	if code == CloseNoStatusReceived {
+		code = 0
+	}
+	_ = c.writeClose(code, "")
+	c.closeConn(nil)
+	return closeErr
+}
+
+// fail sends the close frame with the code matching the error and closes the connection.
+func (c *Conn) fail(err error) error {
+	var (
+		closeErr *CloseError
+		protoErr protocolError
+		closed   bool
+	)
+	select {
+	case <-c.done:
+		closed = true
+	default:
+	}
+	switch {
+	case closed:
+	case errors.As(err, &closeErr):
+		if closeErr.Code == CloseInvalidFramePayloadData {
+			_ = c.writeClose(closeErr.Code, closeErr.Reason)
+		}
+	case errors.As(err, &protoErr):
+		_ = c.writeClose(CloseProtocolError, "")
+	case errors.Is(err, ErrMessageTooLarge):
+		_ = c.writeClose(CloseMessageTooBig, "")
+	}
+	c.closeConn(nil)
+	if c.closeErr != nil {
+		return c.closeErr
+	}
+	return err
+}
+
+// WriteMessage writes the data message. The message is compressed if the `permessage-deflate` extension
+// was negotiated, and fragmented if the Config.FragmentSize is set.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("websocket: unknown message type %d", messageType)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	payload, compressed := data, false
+	if c.deflate.enabled && len(data) > 0 {
+		if c.compressor == nil {
+			level := c.config.CompressionLevel
+			if level == 0 {
+				level = flate.DefaultCompression
+			}
+			compressor, err := flate.NewWriter(nil, level)
+			if err != nil {
+				return err
+			}
+			c.compressor = compressor
+		}
+		var err error
+		if payload, err = compressMessage(c.compressor, data); err != nil {
+			return err
+		}
+		compressed = true
+	}
+	opcode := byte(messageType)
+	for first := true; first || len(payload) > 0; first = false {
+		chunk := payload
+		if c.config.FragmentSize > 0 && len(chunk) > c.config.FragmentSize {
+			chunk = chunk[:c.config.FragmentSize]
+		}
+		payload = payload[len(chunk):]
+		err := writeFrame(c.writer, frame{
+			fin:     len(payload) == 0,
+			rsv1:    compressed && first,
+			opcode:  opcode,
+			masked:  true,
+			payload: chunk,
+		})
+		if err != nil {
+			return c.writeFailed(err)
+		}
+		opcode = opContinuation
+	}
+	if err := c.writer.Flush(); err != nil {
+		return c.writeFailed(err)
+	}
+	return nil
+}
+
+// Ping writes the ping frame with the given payload, the payload should not be longer than 125 bytes.
+func (c *Conn) Ping(data []byte) error {
+	return c.writeControl(opPing, data)
+}
+
+// Close performs the close handshake with the CloseNormalClosure code.
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode sends the close frame with the given code and reason, waits for the close frame of the server
+// (up to the Config.CloseTimeout), and closes the connection. The received data messages are discarded,
+// if no other goroutine is reading the connection.
+func (c *Conn) CloseWithCode(code int, reason string) error {
+	if err := c.writeClose(code, reason); err != nil && !errors.Is(err, ErrCloseSent) {
+		c.closeConn(nil)
+		return err
+	}
+	timer := time.AfterFunc(c.config.CloseTimeout, func() {
+		c.closeConn(nil)
+	})
+	defer timer.Stop()
+	if c.readMu.TryLock() {
+		for {
+			if _, _, err := c.read(); err != nil {
+				break
+			}
+		}
+		c.readMu.Unlock()
+	} else {
+		<-c.done
+	}
+	c.closeConn(nil)
+	return nil
+}
+
+func (c *Conn) writeControl(opcode byte, payload []byte) error {
+	if len(payload) > maxControlPayload {
+		return protocolError("control frame payload is too large")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	return c.writeControlLocked(opcode, payload)
+}
+
+func (c *Conn) writeControlLocked(opcode byte, payload []byte) error {
+	if err := writeFrame(c.writer, frame{fin: true, opcode: opcode, masked: true, payload: payload}); err != nil {
+		return c.writeFailed(err)
+	}
+	if err := c.writer.Flush(); err != nil {
+		return c.writeFailed(err)
+	}
+	return nil
+}
+
+// writeClose sends the close frame, zero code means the frame without the status code.
+func (c *Conn) writeClose(code int, reason string) error {
+	var payload []byte
+	if code != 0 {
+		payload = make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+	}
+	if len(payload) > maxControlPayload {
+		return protocolError("close reason is too long")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	c.closeSent = true
+	return c.writeControlLocked(opClose, payload)
+}
+
+func (c *Conn) writeFailed(err error) error {
+	c.closeConn(err)
+	return err
+}
+
+// closeConn closes the underlying connection once, the cause is returned by the next reads.
+func (c *Conn) closeConn(cause error) {
+	c.closeOnce.Do(func() {
+		c.closeErr = cause
+		close(c.done)
+		_ = c.rwc.Close()
+	})
+}
+
+func (c *Conn) keepalive() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			sent := time.Now().UnixNano()
+			if err := c.writeControl(opPing, nil); err != nil {
+				return
+			}
+			timer := time.NewTimer(c.config.PongTimeout)
+			select {
+			case <-c.done:
+				timer.Stop()
+				return
+			case <-timer.C:
+				if atomic.LoadInt64(&c.lastPong) < sent {
+					c.closeConn(ErrPongTimeout)
+					return
+				}
+			}
+		}
+	}
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	default:
+		return false
+	}
+}
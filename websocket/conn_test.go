@@ -0,0 +1,179 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dial(t *testing.T, server *echoServer, path string, config Config) *Conn {
+	t.Helper()
+	conn, response, err := Dial(context.Background(), nil, "ws"+strings.TrimPrefix(server.URL, "http")+path, config)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Dial() wrong status code = %d", response.StatusCode)
+	}
+	return conn
+}
+
+func TestConn_echo(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "default", config: Config{}},
+		{name: "fragmented", config: Config{FragmentSize: 3}},
+		{name: "compressed", config: Config{Compression: true}},
+		{name: "compressed and fragmented", config: Config{Compression: true, FragmentSize: 4}},
+	}
+	messages := []struct {
+		messageType MessageType
+		data        []byte
+	}{
+		{TextMessage, []byte("hello")},
+		{BinaryMessage, []byte{0, 1, 2, 3, 255}},
+		{TextMessage, []byte(strings.Repeat("large message ", 10000))},
+		{BinaryMessage, []byte{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEchoServer(t)
+			defer server.Close()
+
+			conn := dial(t, server, "/", tt.config)
+			if conn.Compression() != tt.config.Compression {
+				t.Errorf("Compression() = %v", conn.Compression())
+			}
+			for _, message := range messages {
+				if err := conn.WriteMessage(message.messageType, message.data); err != nil {
+					t.Fatalf("WriteMessage() error = %v", err)
+				}
+				messageType, data, err := conn.ReadMessage()
+				if err != nil {
+					t.Fatalf("ReadMessage() error = %v", err)
+				}
+				if messageType != message.messageType || !bytes.Equal(data, message.data) {
+					t.Errorf("ReadMessage() got = %d %.20q, want %d %.20q", messageType, data, message.messageType, message.data)
+				}
+			}
+			if err := conn.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			if !reflect.DeepEqual(server.closeCodesSnapshot(), []int{CloseNormalClosure}) {
+				t.Errorf("wrong close codes = %v", server.closeCodesSnapshot())
+			}
+			compressed := server.compressedSnapshot()
+			if compressed[0] != tt.config.Compression || compressed[len(compressed)-1] {
+				t.Errorf("wrong compressed flags = %v", compressed)
+			}
+		})
+	}
+}
+
+func TestConn_fragmentedResponse(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn := dial(t, server, "/", Config{})
+	defer func() {
+		_ = conn.Close()
+	}()
+	if err := conn.WriteMessage(TextMessage, []byte("fragment:hello world")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil || string(data) != "hello world" {
+		t.Errorf("ReadMessage() got = %q, error = %v", data, err)
+	}
+}
+
+func TestConn_serverClose(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn := dial(t, server, "/", Config{})
+	if err := conn.WriteMessage(TextMessage, []byte("close")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	_, _, err := conn.ReadMessage()
+	closeErr := new(CloseError)
+	if !errors.As(err, &closeErr) || closeErr.Code != 4000 || closeErr.Reason != "bye" {
+		t.Errorf("ReadMessage() wrong error = %v", err)
+	}
+	if _, _, err = conn.ReadMessage(); !errors.As(err, &closeErr) {
+		t.Errorf("ReadMessage() after close wrong error = %v", err)
+	}
+	if err = conn.WriteMessage(TextMessage, []byte("data")); !errors.Is(err, ErrCloseSent) {
+		t.Errorf("WriteMessage() after close wrong error = %v", err)
+	}
+	if !reflect.DeepEqual(server.closeCodesSnapshot(), []int{4000}) {
+		t.Errorf("wrong close codes = %v", server.closeCodesSnapshot())
+	}
+}
+
+func TestConn_maxMessageSize(t *testing.T) {
+	for _, compression := range []bool{false, true} {
+		server := newEchoServer(t)
+
+		conn := dial(t, server, "/", Config{MaxMessageSize: 10, Compression: compression})
+		if err := conn.WriteMessage(BinaryMessage, make([]byte, 100)); err != nil {
+			t.Fatalf("WriteMessage() error = %v", err)
+		}
+		if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("ReadMessage() wrong error = %v", err)
+		}
+		_ = conn.Close()
+		if !reflect.DeepEqual(server.closeCodesSnapshot(), []int{CloseMessageTooBig}) {
+			t.Errorf("wrong close codes = %v", server.closeCodesSnapshot())
+		}
+		server.Close()
+	}
+}
+
+func TestConn_keepalive(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn := dial(t, server, "/", Config{PingInterval: 5 * time.Millisecond})
+	done := make(chan error)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := conn.WriteMessage(TextMessage, []byte("alive")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ReadMessage() error = %v", err)
+	}
+	_ = conn.Close()
+
+	conn = dial(t, server, "/?silent=1", Config{PingInterval: 5 * time.Millisecond})
+	if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrPongTimeout) {
+		t.Errorf("ReadMessage() wrong error = %v", err)
+	}
+}
+
+func TestConn_Ping(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	conn := dial(t, server, "/", Config{})
+	defer func() {
+		_ = conn.Close()
+	}()
+	if err := conn.Ping(make([]byte, 126)); err == nil {
+		t.Errorf("Ping() error wanted")
+	}
+	if err := conn.Ping([]byte("ping")); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package websocket
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/spyzhov/chttp"
+)
+
+const (
+	// DefaultMaxMessageSize is the default limit of the received message size.
+	DefaultMaxMessageSize = 32 << 20
+	// DefaultCloseTimeout is the default time to wait for the close frame of the server.
+	DefaultCloseTimeout = 5 * time.Second
+
+	acceptGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxErrorBodySize = 64 << 10
+)
+
+// ErrBadHandshake is returned if the server response does not complete the opening handshake.
+var ErrBadHandshake = errors.New("websocket: bad handshake")
+
+// Config is a configuration of the WebSocket connection.
+type Config struct {
+	// Header is a list of the additional handshake request headers.
+	Header http.Header
+	// Protocols is a list of the subprotocols offered in the `Sec-WebSocket-Protocol` header.
+	Protocols []string
+	// Compression enables the `permessage-deflate` extension (RFC 7692) offer, messages are compressed
+	// if the server accepts it.
+	Compression bool
+	// CompressionLevel is the compression level from the compress/flate package, flate.DefaultCompression by default.
+	CompressionLevel int
+	// MaxMessageSize is the limit of the received message size, DefaultMaxMessageSize by default.
+	// Negative value means no limit.
+	MaxMessageSize int64
+	// FragmentSize is the maximal payload size of the frames of the sent messages.
+	// Zero means the messages are not fragmented.
+	FragmentSize int
+	// PingInterval is the interval of the keepalive ping frames, zero disables keepalive.
+	PingInterval time.Duration
+	// PongTimeout is the time to wait for the pong frame after the keepalive ping, PingInterval by default.
+	// The connection is closed if the pong frame was not received in time.
+	PongTimeout time.Duration
+	// CloseTimeout is the time to wait for the close frame of the server, DefaultCloseTimeout by default.
+	CloseTimeout time.Duration
+}
+
+// Dial performs the opening handshake with the chttp.Client, so all the client middlewares (headers, auth, trace)
+// are applied to the handshake request. The `ws` and `wss` url schemes are replaced with the `http` and `https`,
+// relative urls are resolved against the client base URL.
+//
+// The client transport should use HTTP/1.1, and middlewares should not replace the response body, because
+// the body of the `101 Switching Protocols` response is used as the connection. The http.Client Timeout
+// is applied to the whole connection lifetime, so it should not be set for the WebSocket clients.
+//
+// If the server responds with another status code, the *chttp.Error is returned with the response.
+func Dial(ctx context.Context, client *chttp.Client, url string, config Config) (*Conn, *http.Response, error) {
+	if client == nil {
+		client = chttp.NewClient(nil)
+	}
+	config = config.withDefaults()
+	url = httpURL(url)
+	key, err := newKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range config.Header {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", key)
+	if len(config.Protocols) > 0 {
+		request.Header.Set("Sec-WebSocket-Protocol", strings.Join(config.Protocols, ", "))
+	}
+	if config.Compression {
+		request.Header.Set("Sec-WebSocket-Extensions", deflateOffer())
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		defer func() {
+			_ = response.Body.Close()
+		}()
+		data, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+		return nil, response, chttp.NewError(response, data, err)
+	}
+	rwc, ok := response.Body.(io.ReadWriteCloser)
+	if !ok {
+		_ = response.Body.Close()
+		return nil, response, fmt.Errorf("%w: response body is not writable", ErrBadHandshake)
+	}
+	deflate, err := checkHandshake(response, key, config)
+	if err != nil {
+		_ = rwc.Close()
+		return nil, response, err
+	}
+	return newConn(rwc, config, deflate, response.Header.Get("Sec-WebSocket-Protocol")), response, nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxMessageSize == 0 {
+		c.MaxMessageSize = DefaultMaxMessageSize
+	}
+	if c.PongTimeout <= 0 {
+		c.PongTimeout = c.PingInterval
+	}
+	if c.CloseTimeout <= 0 {
+		c.CloseTimeout = DefaultCloseTimeout
+	}
+	return c
+}
+
+func checkHandshake(response *http.Response, key string, config Config) (deflateParams, error) {
+	if !strings.EqualFold(response.Header.Get("Upgrade"), "websocket") {
+		return deflateParams{}, fmt.Errorf("%w: unexpected Upgrade header", ErrBadHandshake)
+	}
+	if !headerContains(response.Header, "Connection", "upgrade") {
+		return deflateParams{}, fmt.Errorf("%w: unexpected Connection header", ErrBadHandshake)
+	}
+	if response.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return deflateParams{}, fmt.Errorf("%w: unexpected Sec-WebSocket-Accept header", ErrBadHandshake)
+	}
+	protocol := response.Header.Get("Sec-WebSocket-Protocol")
+	if protocol != "" && !contains(config.Protocols, protocol) {
+		return deflateParams{}, fmt.Errorf("%w: unexpected subprotocol %q", ErrBadHandshake, protocol)
+	}
+	deflate, err := parseDeflateResponse(response.Header.Values("Sec-WebSocket-Extensions"), config.Compression)
+	if err != nil {
+		return deflateParams{}, fmt.Errorf("%w: %v", ErrBadHandshake, err)
+	}
+	return deflate, nil
+}
+
+func httpURL(url string) string {
+	uri, err := neturl.Parse(url)
+	if err != nil {
+		return url
+	}
+	switch strings.ToLower(uri.Scheme) {
+	case "ws":
+		uri.Scheme = "http"
+	case "wss":
+		uri.Scheme = "https"
+	default:
+		return url
+	}
+	return uri.String()
+}
+
+func newKey() (string, error) {
+	var key [16]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key[:]), nil
+}
+
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spyzhov/chttp"
+)
+
+func TestDial(t *testing.T) {
+	server := newEchoServer(t)
+	defer server.Close()
+
+	client := chttp.NewClient(nil, chttp.WithBaseURL(server.URL))
+	client.With(func(request *http.Request, next func(request *http.Request) (*http.Response, error)) (*http.Response, error) {
+		request.Header.Set("Authorization", "Bearer token")
+		return next(request)
+	})
+	conn, _, err := Dial(context.Background(), client, "/chat", Config{
+		Header:    http.Header{"X-Request-Id": {"42"}},
+		Protocols: []string{"chat.v2", "chat.v1"},
+	})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if conn.Subprotocol() != "chat.v2" {
+		t.Errorf("Subprotocol() = %q", conn.Subprotocol())
+	}
+	header := server.requestHeader()
+	if header.Get("Authorization") != "Bearer token" || header.Get("X-Request-Id") != "42" {
+		t.Errorf("wrong handshake headers = %v", header)
+	}
+}
+
+func TestDial_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		check   func(err error) bool
+	}{
+		{
+			name: "status code",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusForbidden)
+			},
+			check: func(err error) bool {
+				cErr := new(chttp.Error)
+				return errors.As(err, &cErr) && cErr.IsStatusCode() && strings.HasPrefix(err.Error(), "GET http://")
+			},
+		},
+		{
+			name: "wrong accept",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Upgrade", "websocket")
+				writer.Header().Set("Connection", "Upgrade")
+				writer.Header().Set("Sec-WebSocket-Accept", "wrong")
+				writer.WriteHeader(http.StatusSwitchingProtocols)
+			},
+			check: func(err error) bool {
+				return errors.Is(err, ErrBadHandshake)
+			},
+		},
+		{
+			name: "unexpected extension",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Upgrade", "websocket")
+				writer.Header().Set("Connection", "Upgrade")
+				writer.Header().Set("Sec-WebSocket-Accept", acceptKey(request.Header.Get("Sec-WebSocket-Key")))
+				writer.Header().Set("Sec-WebSocket-Extensions", "permessage-deflate")
+				writer.WriteHeader(http.StatusSwitchingProtocols)
+			},
+			check: func(err error) bool {
+				return errors.Is(err, ErrBadHandshake)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newEchoServer(t)
+			server.Config.Handler = tt.handler
+			defer server.Close()
+
+			_, _, err := Dial(context.Background(), nil, server.URL, Config{})
+			if !tt.check(err) {
+				t.Errorf("Dial() wrong error = %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPURL(t *testing.T) {
+	tests := map[string]string{
+		"ws://example.com/chat":  "http://example.com/chat",
+		"WSS://example.com/chat": "https://example.com/chat",
+		"https://example.com/":   "https://example.com/",
+		"/relative?query=1":      "/relative?query=1",
+	}
+	for url, want := range tests {
+		if got := httpURL(url); got != want {
+			t.Errorf("httpURL(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+// Package websocket provides the WebSocket (RFC 6455) client on top of the cHTTP clients.
+package websocket
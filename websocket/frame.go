@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	opContinuation byte = 0x0
+	opText         byte = 0x1
+	opBinary       byte = 0x2
+	opClose        byte = 0x8
+	opPing         byte = 0x9
+	opPong         byte = 0xa
+
+	maxControlPayload = 125
+)
+
+// ErrMessageTooLarge is returned if the received message exceeds the Config.MaxMessageSize limit.
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// protocolError is a violation of the RFC 6455, the connection is failed with the CloseProtocolError code.
+type protocolError string
+
+func (e protocolError) Error() string {
+	return "websocket: protocol error: " + string(e)
+}
+
+type frame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	masked  bool
+	payload []byte
+}
+
+func isControl(opcode byte) bool {
+	return opcode&0x8 != 0
+}
+
+// readFrame reads the frame and unmasks its payload. The payload larger than the limit is not read,
+// negative limit means no limit.
+func readFrame(reader *bufio.Reader, limit int64) (frame, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{
+		fin:    header[0]&0x80 != 0,
+		rsv1:   header[0]&0x40 != 0,
+		opcode: header[0] & 0x0f,
+		masked: header[1]&0x80 != 0,
+	}
+	if header[0]&0x30 != 0 {
+		return f, protocolError("reserved bits are set")
+	}
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(reader, ext[:]); err != nil {
+			return f, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(reader, ext[:]); err != nil {
+			return f, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+		if length < 0 {
+			return f, protocolError("invalid payload length")
+		}
+	}
+	if isControl(f.opcode) {
+		if !f.fin {
+			return f, protocolError("fragmented control frame")
+		}
+		if length > maxControlPayload {
+			return f, protocolError("control frame payload is too large")
+		}
+	}
+	if limit >= 0 && length > limit {
+		return f, ErrMessageTooLarge
+	}
+	var key [4]byte
+	if f.masked {
+		if _, err := io.ReadFull(reader, key[:]); err != nil {
+			return f, err
+		}
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(reader, f.payload); err != nil {
+		return f, err
+	}
+	if f.masked {
+		maskBytes(key, f.payload)
+	}
+	return f, nil
+}
+
+// writeFrame writes the frame, the payload is masked with a random key if the frame is masked.
+func writeFrame(writer io.Writer, f frame) error {
+	header := make([]byte, 2, 14)
+	header[0] = f.opcode
+	if f.fin {
+		header[0] |= 0x80
+	}
+	if f.rsv1 {
+		header[0] |= 0x40
+	}
+	length := len(f.payload)
+	switch {
+	case length <= maxControlPayload:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, byte(length>>8), byte(length))
+	default:
+		header[1] = 127
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(length))
+		header = append(header, ext[:]...)
+	}
+	payload := f.payload
+	if f.masked {
+		header[1] |= 0x80
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header = append(header, key[:]...)
+		payload = append([]byte(nil), payload...)
+		maskBytes(key, payload)
+	}
+	if _, err := writer.Write(header); err != nil {
+		return err
+	}
+	_, err := writer.Write(payload)
+	return err
+}
+
+func maskBytes(key [4]byte, data []byte) {
+	for i := range data {
+		data[i] ^= key[i&3]
+	}
+}
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFrame_roundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 125, 126, 0xffff, 0x10000} {
+		for _, masked := range []bool{false, true} {
+			payload := bytes.Repeat([]byte{0xa5}, size)
+			var buffer bytes.Buffer
+			err := writeFrame(&buffer, frame{fin: true, rsv1: true, opcode: opBinary, masked: masked, payload: payload})
+			if err != nil {
+				t.Fatalf("writeFrame() error = %v", err)
+			}
+			if masked {
+				// the payload is at the end of the frame, right after the masking key
+				data := buffer.Bytes()
+				body := data[len(data)-size:]
+				key := data[len(data)-size-4 : len(data)-size]
+				for i := range body {
+					if body[i] != payload[i]^key[i%4] {
+						t.Fatalf("writeFrame() payload of size %d is not masked with the key %x", size, key)
+					}
+				}
+			}
+			f, err := readFrame(bufio.NewReader(&buffer), -1)
+			if err != nil {
+				t.Fatalf("readFrame() error = %v", err)
+			}
+			if !f.fin || !f.rsv1 || f.opcode != opBinary || f.masked != masked || !bytes.Equal(f.payload, payload) {
+				t.Errorf("readFrame() wrong frame of size %d: fin=%v rsv1=%v opcode=%d masked=%v",
+					size, f.fin, f.rsv1, f.opcode, f.masked)
+			}
+		}
+	}
+}
+
+func TestReadFrame_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		limit int64
+		want  error
+	}{
+		{name: "reserved bits", data: []byte{0x80 | 0x20 | opText, 0}, limit: -1, want: protocolError("")},
+		{name: "fragmented control", data: []byte{opPing, 0}, limit: -1, want: protocolError("")},
+		{name: "large control", data: []byte{0x80 | opPing, 126, 0, 126}, limit: -1, want: protocolError("")},
+		{name: "limit", data: []byte{0x80 | opText, 11}, limit: 10, want: ErrMessageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readFrame(bufio.NewReader(bytes.NewReader(tt.data)), tt.limit)
+			var protoErr protocolError
+			if errors.As(tt.want, &protoErr) {
+				if !errors.As(err, &protoErr) {
+					t.Errorf("readFrame() wrong error = %v", err)
+				}
+			} else if !errors.Is(err, tt.want) {
+				t.Errorf("readFrame() wrong error = %v", err)
+			}
+		})
+	}
+}
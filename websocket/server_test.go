@@ -0,0 +1,186 @@
+package websocket
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// echoServer is a minimal RFC 6455 server, which echoes all data messages back.
+//
+// Special text messages:
+//   - `close` - the server starts the close handshake with the code 4000 and the reason `bye`;
+//   - `fragment:<text>` - the text is sent back in 2-byte fragments with the ping frames in between.
+//
+// Pings are answered with pongs unless the `silent` query parameter is set. Received close frames are recorded.
+// The server does not wait for the connections on close, because they are hijacked.
+type echoServer struct {
+	*httptest.Server
+	t *testing.T
+
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	closeCodes []int
+	compressed []bool
+	header     http.Header
+}
+
+func newEchoServer(t *testing.T) *echoServer {
+	server := &echoServer{t: t}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+func (s *echoServer) handle(writer http.ResponseWriter, request *http.Request) {
+	s.wg.Add(1)
+	defer s.wg.Done()
+	if !strings.EqualFold(request.Header.Get("Upgrade"), "websocket") ||
+		request.Header.Get("Sec-WebSocket-Version") != "13" {
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte("not a websocket handshake"))
+		return
+	}
+	s.mu.Lock()
+	s.header = request.Header.Clone()
+	s.mu.Unlock()
+
+	deflate := strings.Contains(request.Header.Get("Sec-WebSocket-Extensions"), extensionDeflate)
+	conn, rw, err := writer.(http.Hijacker).Hijack()
+	if err != nil {
+		s.t.Errorf("Hijack() error = %v", err)
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(request.Header.Get("Sec-WebSocket-Key")) + "\r\n"
+	if protocols := request.Header.Get("Sec-WebSocket-Protocol"); protocols != "" {
+		response += "Sec-WebSocket-Protocol: " + strings.TrimSpace(strings.Split(protocols, ",")[0]) + "\r\n"
+	}
+	if deflate {
+		response += "Sec-WebSocket-Extensions: " + deflateOffer() + "\r\n"
+	}
+	_, _ = rw.WriteString(response + "\r\n")
+	_ = rw.Flush()
+
+	s.serve(rw, deflate, request.URL.Query().Get("silent") != "")
+}
+
+func (s *echoServer) serve(rw *bufio.ReadWriter, deflate bool, silent bool) {
+	compressor, _ := flate.NewWriter(nil, flate.BestSpeed)
+	write := func(f frame) {
+		_ = writeFrame(rw, f)
+		_ = rw.Flush()
+	}
+	var (
+		opcode     byte
+		compressed bool
+		closing    bool
+		message    []byte
+	)
+	for {
+		f, err := readFrame(rw.Reader, -1)
+		if err != nil {
+			return
+		}
+		if !f.masked {
+			write(frame{fin: true, opcode: opClose, payload: closePayload(CloseProtocolError, "unmasked frame")})
+			return
+		}
+		switch f.opcode {
+		case opPing:
+			if !silent {
+				write(frame{fin: true, opcode: opPong, payload: f.payload})
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			code := CloseNoStatusReceived
+			if len(f.payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(f.payload))
+			}
+			s.mu.Lock()
+			s.closeCodes = append(s.closeCodes, code)
+			s.mu.Unlock()
+			if !closing {
+				write(frame{fin: true, opcode: opClose, payload: f.payload})
+			}
+			return
+		case opText, opBinary:
+			opcode, compressed, message = f.opcode, f.rsv1, nil
+		}
+		message = append(message, f.payload...)
+		if !f.fin {
+			continue
+		}
+		s.mu.Lock()
+		s.compressed = append(s.compressed, compressed)
+		s.mu.Unlock()
+		if compressed {
+			if message, err = decompressMessage(message, nil, -1); err != nil {
+				s.t.Errorf("decompressMessage() error = %v", err)
+				return
+			}
+		}
+
+		switch text := string(message); {
+		case opcode == opText && text == "close":
+			write(frame{fin: true, opcode: opClose, payload: closePayload(4000, "bye")})
+			closing = true
+		case opcode == opText && strings.HasPrefix(text, "fragment:"):
+			payload := []byte(strings.TrimPrefix(text, "fragment:"))
+			for i := 0; i < len(payload); i += 2 {
+				end := i + 2
+				if end > len(payload) {
+					end = len(payload)
+				}
+				op := opContinuation
+				if i == 0 {
+					op = opText
+				}
+				write(frame{fin: end == len(payload), opcode: op, payload: payload[i:end]})
+				write(frame{fin: true, opcode: opPing, payload: []byte("ping")})
+			}
+		case deflate && len(message) > 0:
+			payload, _ := compressMessage(compressor, message)
+			write(frame{fin: true, rsv1: true, opcode: opcode, payload: payload})
+		default:
+			write(frame{fin: true, opcode: opcode, payload: message})
+		}
+	}
+}
+
+// closeCodesSnapshot waits for all connections to finish and returns the received close codes.
+func (s *echoServer) closeCodesSnapshot() []int {
+	s.wg.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.closeCodes...)
+}
+
+func (s *echoServer) compressedSnapshot() []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]bool(nil), s.compressed...)
+}
+
+func (s *echoServer) requestHeader() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+func closePayload(code int, reason string) []byte {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	return append(payload, reason...)
+}